@@ -1,29 +1,59 @@
 package server
 
 import (
+	"context"
 	"product-service/config"
 	"product-service/internal"
-	"product-service/internal/entity"
+	"product-service/internal/inventory"
 	"product-service/internal/middleware"
+	"product-service/internal/migration"
+	"product-service/internal/notification"
 	"product-service/internal/pkg"
+	"product-service/internal/rendition"
+	"product-service/internal/repository"
+	"product-service/internal/storage"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
 )
 
 func StartServer() {
 	db := config.ConnectDB()
+	store := config.ConnectStorage()
+	config.ConfigureCursorSigning()
 
-	db.AutoMigrate(
-		&entity.Category{},
-	)
+	if err := migration.Run(db, config.DefaultCurrency()); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	renditions := rendition.NewPool(repository.NewProductImageRepository(db), store,
+		config.RenditionSpecs(), config.RenditionWorkers())
+	renditions.Start(context.Background())
+
+	sweeper := inventory.NewReservationSweeper(repository.NewReservationRepository(db),
+		config.ReservationSweepInterval())
+	sweeper.Start(context.Background())
+
+	dispatcher := notification.NewDispatcher(repository.NewNotificationRepository(db),
+		config.NotificationSender(), config.NotificationDispatchInterval())
+	dispatcher.Start(context.Background())
 
 	e := echo.New()
-	e.Validator = &pkg.CustomValidator{Validator: validator.New()}
+	e.Validator = pkg.NewCustomValidator()
 
 	middleware.RegisterBasicMiddleware(e)
 
+	if local, ok := store.(*storage.LocalStorage); ok {
+		e.Static(local.BaseURL, local.Dir)
+	}
+
 	internal.RegisterCategoryRoutes(e, db)
+	internal.RegisterProductRoutes(e, db, store)
+	internal.RegisterAttributeRoutes(e, db)
+	internal.RegisterProductImageRoutes(e, db, store, renditions)
+	internal.RegisterReservationRoutes(e, db, config.ReservationTTL())
+	internal.RegisterWarehouseRoutes(e, db)
+	internal.RegisterStockSubscriptionRoutes(e, db)
 
 	e.Logger.Fatal(e.Start(":8080"))
 }
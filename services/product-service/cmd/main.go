@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"product-service/cmd/maintenance"
 	"product-service/cmd/server"
 
 	"github.com/spf13/cobra"
@@ -29,6 +30,8 @@ func init() {
 	rootCmd.AddCommand(server.StartLocalCmd)
 	rootCmd.AddCommand(server.StartProductionCmd)
 	rootCmd.AddCommand(server.StartStagingCmd)
+	rootCmd.AddCommand(maintenance.BackfillCategoryPathsCmd)
+	rootCmd.AddCommand(maintenance.ReconcileStockCmd)
 }
 
 func Execute() {
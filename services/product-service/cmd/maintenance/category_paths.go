@@ -0,0 +1,40 @@
+package maintenance
+
+import (
+	"fmt"
+	"log"
+	"product-service/config"
+	"product-service/internal/migration"
+	"product-service/internal/repository"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var BackfillCategoryPathsCmd = &cobra.Command{
+	Use:   "backfill-category-paths",
+	Short: "Recompute materialized category paths and depths from parent_id",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, _ := cmd.Flags().GetString("env")
+		envFile := ".env." + env
+		if err := godotenv.Load(envFile); err != nil {
+			log.Printf("Warning: %s file not found, using environment variables", envFile)
+		}
+
+		db := config.ConnectDB()
+		if err := migration.Run(db, config.DefaultCurrency()); err != nil {
+			return fmt.Errorf("migrate database: %w", err)
+		}
+
+		updated, err := repository.RebuildCategoryPaths(db)
+		if err != nil {
+			return fmt.Errorf("rebuild category paths: %w", err)
+		}
+		fmt.Printf("Updated %d category paths\n", updated)
+		return nil
+	},
+}
+
+func init() {
+	BackfillCategoryPathsCmd.Flags().String("env", "local", "environment whose .env file to load (local, staging or production)")
+}
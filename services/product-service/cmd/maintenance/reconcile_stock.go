@@ -0,0 +1,59 @@
+package maintenance
+
+import (
+	"fmt"
+	"log"
+	"product-service/config"
+	"product-service/internal/migration"
+	"product-service/internal/repository"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var ReconcileStockCmd = &cobra.Command{
+	Use:   "reconcile-stock",
+	Short: "Report variants whose stock differs from the stock movement ledger",
+	Long: "Compares the stock and reserved totals of every variant with the sums of its stock movements.\n" +
+		"Exits with an error if any variant drifted; with --fix the stock is reset to the ledger instead.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, _ := cmd.Flags().GetString("env")
+		envFile := ".env." + env
+		if err := godotenv.Load(envFile); err != nil {
+			log.Printf("Warning: %s file not found, using environment variables", envFile)
+		}
+
+		db := config.ConnectDB()
+		if err := migration.Run(db, config.DefaultCurrency()); err != nil {
+			return fmt.Errorf("migrate database: %w", err)
+		}
+
+		drift, err := repository.FindStockDrift(db)
+		if err != nil {
+			return fmt.Errorf("find stock drift: %w", err)
+		}
+		for _, d := range drift {
+			fmt.Printf("variant %d (%s): stock %d, ledger %d; reserved %d, ledger %d\n",
+				d.VariantID, d.SKU, d.Stock, d.LedgerStock, d.Reserved, d.LedgerReserved)
+		}
+		if len(drift) == 0 {
+			fmt.Println("Stock matches the ledger")
+			return nil
+		}
+
+		if fix, _ := cmd.Flags().GetBool("fix"); !fix {
+			return fmt.Errorf("%d variants drifted from the stock ledger", len(drift))
+		}
+		corrected, err := repository.RebuildStockFromLedger(db)
+		if err != nil {
+			return fmt.Errorf("rebuild stock from ledger: %w", err)
+		}
+		fmt.Printf("Reset stock of %d variants to the ledger\n", corrected)
+		return nil
+	},
+}
+
+func init() {
+	ReconcileStockCmd.Flags().String("env", "local", "environment whose .env file to load (local, staging or production)")
+	ReconcileStockCmd.Flags().Bool("fix", false, "reset warehouse stock and variant totals to the ledger sums")
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"product-service/internal/pkg"
+
+	"github.com/labstack/gommon/log"
+)
+
+// DefaultCurrency reads DEFAULT_CURRENCY, the ISO 4217 currency prices are
+// taken to be in when they are migrated from before prices had a currency.
+func DefaultCurrency() string {
+	currency := os.Getenv("DEFAULT_CURRENCY")
+	if currency == "" {
+		return "USD"
+	}
+	if !pkg.IsCurrency(currency) {
+		log.Fatalf("Invalid DEFAULT_CURRENCY: %q is not an ISO 4217 currency code", currency)
+	}
+	return currency
+}
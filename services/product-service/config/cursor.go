@@ -0,0 +1,18 @@
+package config
+
+import (
+	"os"
+	"product-service/internal/pkg"
+
+	"github.com/labstack/gommon/log"
+)
+
+// ConfigureCursorSigning loads the key used to sign pagination cursors. All
+// instances behind the same load balancer must share CURSOR_SECRET.
+func ConfigureCursorSigning() {
+	secret := os.Getenv("CURSOR_SECRET")
+	if secret == "" {
+		log.Warn("CURSOR_SECRET not set, using a random key; cursors will not survive restarts")
+	}
+	pkg.SetCursorSecret([]byte(secret))
+}
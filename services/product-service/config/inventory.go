@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// ReservationTTL reads RESERVATION_TTL, e.g. "15m", the time a stock
+// reservation is held before the sweeper releases it.
+func ReservationTTL() time.Duration {
+	return durationEnv("RESERVATION_TTL", 15*time.Minute)
+}
+
+// ReservationSweepInterval reads RESERVATION_SWEEP_INTERVAL, how often
+// expired reservations are looked for.
+func ReservationSweepInterval() time.Duration {
+	return durationEnv("RESERVATION_SWEEP_INTERVAL", time.Minute)
+}
+
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s: %q must be a positive duration", name, raw)
+	}
+	return d
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"product-service/internal/notification"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// NotificationSender reads NOTIFICATION_SENDER, the sender stock
+// notifications are delivered through. Only "log" is supported so far.
+func NotificationSender() notification.Sender {
+	sender := os.Getenv("NOTIFICATION_SENDER")
+	if sender == "" {
+		sender = "log"
+	}
+
+	switch sender {
+	case "log":
+		return notification.NewLogSender()
+	default:
+		log.Fatalf("Unsupported notification sender: %s", sender)
+	}
+	return nil
+}
+
+// NotificationDispatchInterval reads NOTIFICATION_DISPATCH_INTERVAL, how often
+// queued stock notifications are looked for.
+func NotificationDispatchInterval() time.Duration {
+	return durationEnv("NOTIFICATION_DISPATCH_INTERVAL", 10*time.Second)
+}
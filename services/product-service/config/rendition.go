@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"product-service/internal/rendition"
+	"strconv"
+
+	"github.com/labstack/gommon/log"
+)
+
+// RenditionSpecs reads IMAGE_RENDITIONS, e.g.
+// "thumbnail:200x200,medium:600x600,webp:1200x1200:webp", falling back to
+// rendition.DefaultSpecs.
+func RenditionSpecs() []rendition.Spec {
+	raw := os.Getenv("IMAGE_RENDITIONS")
+	if raw == "" {
+		return rendition.DefaultSpecs
+	}
+	specs, err := rendition.ParseSpecs(raw)
+	if err != nil {
+		log.Fatalf("Invalid IMAGE_RENDITIONS: %v", err)
+	}
+	return specs
+}
+
+func RenditionWorkers() int {
+	workers, err := strconv.Atoi(os.Getenv("IMAGE_RENDITION_WORKERS"))
+	if err != nil || workers < 1 {
+		return 2
+	}
+	return workers
+}
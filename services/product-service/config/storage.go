@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"product-service/internal/storage"
+
+	"github.com/labstack/gommon/log"
+)
+
+func ConnectStorage() storage.Storage {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+
+	switch driver {
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		baseURL := os.Getenv("STORAGE_BASE_URL")
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+
+		store, err := storage.NewLocalStorage(dir, baseURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize local storage: %v", err)
+		}
+		return store
+	default:
+		log.Fatalf("Unsupported storage driver: %s", driver)
+	}
+	return nil
+}
@@ -4,9 +4,17 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	AttributeTypeText   = "text"
+	AttributeTypeColor  = "color"
+	AttributeTypeNumber = "number"
+)
+
 type Attribute struct {
 	gorm.Model
 	ID     uint             `gorm:"primaryKey"`
-	Name   string           `gorm:"unique;not null"`
+	Name   string           `gorm:"not null;uniqueIndex:idx_attributes_name_live,where:deleted_at IS NULL"`
+	Type   string           `gorm:"not null;default:'text'"` // text, color or number
+	Unit   string           // e.g., cm, kg; only meaningful for number attributes
 	Values []AttributeValue `gorm:"foreignKey:AttributeID"`
 }
@@ -8,10 +8,11 @@ import (
 
 type AttributeValue struct {
 	gorm.Model
-	ID          uint      `gorm:"primaryKey"`
-	AttributeID uint      `gorm:"not null"`
+	ID          uint `gorm:"primaryKey"`
+	AttributeID uint `gorm:"not null;uniqueIndex:idx_attribute_values_attribute_value_live,where:deleted_at IS NULL"`
 	Attribute   Attribute
-	Value       string    `gorm:"not null"` // e.g., S, M, L or Red, Blue
+	Value       string `gorm:"not null;uniqueIndex:idx_attribute_values_attribute_value_live,where:deleted_at IS NULL"` // e.g., S, M, L or Red, Blue
+	Swatch      string // hex color such as #FF0000 for color attributes
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
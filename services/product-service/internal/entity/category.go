@@ -6,9 +6,13 @@ import (
 
 type Category struct {
 	gorm.Model
-	Name        string      `gorm:"unique;not null"`
+	Name        string      `gorm:"not null;uniqueIndex:idx_categories_name_live,where:deleted_at IS NULL"`
+	Slug        string      `json:"slug" gorm:"uniqueIndex:idx_categories_slug_live,where:deleted_at IS NULL"`
 	Description string      `json:"description,omitempty"`
 	ParentID    *uint       `json:"parent_id,omitempty"`
+	Position    int         `json:"position" gorm:"not null;default:0"` // order among siblings
+	Path        string      `json:"path" gorm:"not null;default:''"`    // ancestor IDs, e.g. /1/4/9/
+	Depth       int         `json:"depth" gorm:"not null;default:0"`
 	Children    []*Category `json:"children,omitempty" gorm:"foreignKey:ParentID"`
 	Products    []*Product  `json:"products,omitempty" gorm:"foreignKey:CategoryID"`
 }
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"product-service/internal/pkg"
+
 	"gorm.io/gorm"
 )
 
@@ -8,10 +10,11 @@ type Product struct {
 	gorm.Model
 	ID          uint           `gorm:"primaryKey"`
 	Name        string         `gorm:"not null"`
+	Slug        string         `gorm:"uniqueIndex:idx_products_slug_live,where:deleted_at IS NULL"`
 	Description string
 	CategoryID  uint           `gorm:"not null"`
 	Category    Category
-	Price       float64        `gorm:"not null"`
+	Price       pkg.Money      `gorm:"embedded;embeddedPrefix:price_"`
 	CreatedBy   uint           `gorm:"not null"`
 	Images      []ProductImage `gorm:"foreignKey:ProductID"`
 	Variants    []Variant      `gorm:"foreignKey:ProductID"`
@@ -6,12 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RenditionStatusPending = "pending"
+	RenditionStatusReady   = "ready"
+	RenditionStatusFailed  = "failed"
+)
+
 type ProductImage struct {
 	gorm.Model
-	ID        uint      `gorm:"primaryKey"`
-	ProductID uint      `gorm:"not null"`
-	URL       string    `gorm:"not null"`
-	IsPrimary bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               uint   `gorm:"primaryKey"`
+	ProductID        uint   `gorm:"not null;index;uniqueIndex:idx_product_images_primary,where:is_primary AND deleted_at IS NULL"`
+	URL              string `gorm:"not null"`
+	StorageKey       string `gorm:"not null"`
+	ContentType      string
+	Size             int64
+	IsPrimary        bool
+	Position         int                     `gorm:"not null;default:0"`
+	RenditionStatus  string                  `gorm:"not null;default:'pending'"` // pending, ready or failed
+	RenditionVersion int                     `gorm:"not null;default:0"`         // bumped on every regeneration request
+	Renditions       []ProductImageRendition `gorm:"foreignKey:ProductImageID"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+type ProductImageRendition struct {
+	gorm.Model
+	ProductImageID uint   `gorm:"not null;uniqueIndex:idx_product_image_renditions_name"`
+	Name           string `gorm:"not null;uniqueIndex:idx_product_image_renditions_name"` // e.g., thumbnail, medium, webp
+	URL            string `gorm:"not null"`
+	StorageKey     string `gorm:"not null"`
+	ContentType    string
+	Width          int
+	Height         int
+}
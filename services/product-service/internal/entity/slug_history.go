@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	SlugEntityCategory = "category"
+	SlugEntityProduct  = "product"
+)
+
+// SlugHistory keeps the slugs an entity used before it was renamed so old
+// URLs can be redirected.
+type SlugHistory struct {
+	gorm.Model
+	EntityType string `gorm:"not null;uniqueIndex:idx_slug_histories_entity_slug"` // category or product
+	EntityID   uint   `gorm:"not null;index"`
+	Slug       string `gorm:"not null;uniqueIndex:idx_slug_histories_entity_slug"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+const (
+	StockMovementReceipt     = "receipt"
+	StockMovementSale        = "sale"
+	StockMovementReturn      = "return"
+	StockMovementAdjustment  = "adjustment"
+	StockMovementReservation = "reservation"
+	StockMovementTransfer    = "transfer"
+)
+
+// StockMovement is an entry of the stock ledger: one change to the stock of
+// a variant in one warehouse. Delta changes the quantity on hand and
+// ReservedDelta the quantity reserved, so a WarehouseStock row always equals
+// the sums over its movements. Rows are never updated or deleted while their
+// variant exists.
+type StockMovement struct {
+	ID            uint   `gorm:"primaryKey"`
+	VariantID     uint   `gorm:"not null;index:idx_stock_movements_variant_warehouse"`
+	WarehouseID   uint   `gorm:"not null;index:idx_stock_movements_variant_warehouse"`
+	Type          string `gorm:"not null"` // receipt, sale, return, adjustment, reservation or transfer
+	Delta         int    `gorm:"not null;default:0"`
+	ReservedDelta int    `gorm:"not null;default:0"`
+	Reason        string `gorm:"not null;default:''"`
+	Actor         string `gorm:"not null;default:''"`
+	// Reference ties the movement to what caused it: an order for sales and
+	// reservations, the pair of movements making up a transfer, or any
+	// document the client passes such as a delivery note.
+	Reference string    `gorm:"not null;default:'';index"`
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StockNotificationLowStock    = "low_stock"
+	StockNotificationBackInStock = "back_in_stock"
+)
+
+const (
+	NotificationStatusPending = "pending"
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed"
+)
+
+// StockSubscription is a customer waiting for an out-of-stock variant. It is
+// notified once, the next time the variant's stock goes from zero to
+// positive; an email holds at most one waiting subscription per variant.
+type StockSubscription struct {
+	gorm.Model
+	VariantID  uint   `gorm:"not null;index;uniqueIndex:idx_stock_subscriptions_waiting,where:notified_at IS NULL AND deleted_at IS NULL"`
+	Email      string `gorm:"not null;uniqueIndex:idx_stock_subscriptions_waiting,where:notified_at IS NULL AND deleted_at IS NULL"`
+	NotifiedAt *time.Time
+}
+
+// StockNotification is a queued stock notification, written in the same
+// transaction as the stock change causing it and delivered afterwards by the
+// notification dispatcher. Low-stock notifications have no recipient; the
+// sender decides where they go.
+type StockNotification struct {
+	ID             uint   `gorm:"primaryKey"`
+	Type           string `gorm:"not null"` // low_stock or back_in_stock
+	VariantID      uint   `gorm:"not null;index"`
+	SKU            string `gorm:"not null"`
+	Available      int    `gorm:"not null"`
+	Threshold      *int
+	Recipient      string `gorm:"not null;default:''"`
+	SubscriptionID *uint
+	Status         string    `gorm:"not null;default:'pending';index:idx_stock_notifications_due,priority:1"` // pending, sent or failed
+	SendAfter      time.Time `gorm:"not null;index:idx_stock_notifications_due,priority:2"`
+	Attempts       int       `gorm:"not null;default:0"`
+	LastError      string    `gorm:"not null;default:''"`
+	SentAt         *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+// StockReservation holds Quantity units of a variant in one warehouse for an
+// order until the order commits or releases it, or ExpiresAt passes. An order
+// holds at most one active reservation per variant and warehouse.
+type StockReservation struct {
+	gorm.Model
+	OrderRef    string `gorm:"not null;index;uniqueIndex:idx_stock_reservations_active_stock,where:status = 'active' AND deleted_at IS NULL"`
+	VariantID   uint   `gorm:"not null;index;uniqueIndex:idx_stock_reservations_active_stock,where:status = 'active' AND deleted_at IS NULL"`
+	Variant     Variant
+	WarehouseID *uint     `gorm:"index;uniqueIndex:idx_stock_reservations_active_stock,where:status = 'active' AND deleted_at IS NULL"`
+	Quantity    int       `gorm:"not null"`
+	Status      string    `gorm:"not null;default:'active';index"` // active, committed, released or expired
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
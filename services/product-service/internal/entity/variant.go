@@ -1,16 +1,24 @@
 package entity
 
 import (
+	"product-service/internal/pkg"
+
 	"gorm.io/gorm"
 )
 
 type Variant struct {
 	gorm.Model
-	ID         uint             `gorm:"primaryKey"`
-	ProductID  uint             `gorm:"not null"`
-	Product    Product
-	SKU        string           `gorm:"uniqueIndex;not null"`
-	Price      float64
-	Stock      int
-	Attributes []AttributeValue `gorm:"many2many:variant_attribute_values;"`
+	ID        uint `gorm:"primaryKey"`
+	ProductID uint `gorm:"not null"`
+	Product   Product
+	SKU       string    `gorm:"uniqueIndex:idx_variants_sku_live,where:deleted_at IS NULL;not null"`
+	Price     pkg.Money `gorm:"embedded;embeddedPrefix:price_"`
+	Stock     int
+	// Reserved is the part of Stock held by active reservations; only
+	// Stock - Reserved can be sold.
+	Reserved int `gorm:"not null;default:0;check:chk_variants_reserved,reserved >= 0 AND reserved <= stock"`
+	// ReorderThreshold raises a low-stock notification when Stock - Reserved
+	// drops below it; nil disables the alert.
+	ReorderThreshold *int
+	Attributes       []AttributeValue `gorm:"many2many:variant_attribute_values;"`
 }
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Warehouse is a location holding stock. Exactly one warehouse is the
+// default, which receives the stock set through the variant API.
+type Warehouse struct {
+	gorm.Model
+	Code      string `gorm:"not null;uniqueIndex:idx_warehouses_code_live,where:deleted_at IS NULL"`
+	Name      string `gorm:"not null"`
+	Priority  int    `gorm:"not null;default:0"` // lower is allocated first
+	IsDefault bool   `gorm:"not null;default:false;uniqueIndex:idx_warehouses_default,where:is_default AND deleted_at IS NULL"`
+	Latitude  *float64
+	Longitude *float64
+}
+
+// WarehouseStock is the stock of one variant in one warehouse. Variant.Stock
+// and Variant.Reserved are the totals over all of these rows.
+type WarehouseStock struct {
+	ID          uint `gorm:"primaryKey"`
+	VariantID   uint `gorm:"not null;uniqueIndex:idx_warehouse_stocks_variant_warehouse"`
+	WarehouseID uint `gorm:"not null;uniqueIndex:idx_warehouse_stocks_variant_warehouse;index"`
+	Warehouse   Warehouse
+	Quantity    int `gorm:"not null;default:0"`
+	Reserved    int `gorm:"not null;default:0;check:chk_warehouse_stocks_reserved,reserved >= 0 AND reserved <= quantity"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerActor    = "X-Actor"
+	anonymousActor = "anonymous"
+)
+
+// actor names who made a request for the stock ledger, taken from the
+// X-Actor header.
+func actor(c echo.Context) string {
+	if a := strings.TrimSpace(c.Request().Header.Get(headerActor)); a != "" {
+		return a
+	}
+	return anonymousActor
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+var attributeListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id", "name": "name"},
+	DefaultSort: "id:asc",
+	Filters: map[string]pkg.FilterField{
+		"name": {Column: "name", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+		"type": {Column: "type", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}},
+	},
+}
+
+var attributeValueListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id", "value": "value"},
+	DefaultSort: "id:asc",
+	Filters: map[string]pkg.FilterField{
+		"value": {Column: "value", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+	},
+}
+
+type AttributeHandler struct {
+	attributeUsecase *usecase.AttributeUsecase
+}
+
+func NewAttributeHandler(attributeUsecase *usecase.AttributeUsecase) *AttributeHandler {
+	return &AttributeHandler{attributeUsecase: attributeUsecase}
+}
+
+func (h *AttributeHandler) GetAllAttributes(c echo.Context) error {
+	q, err := pkg.ParseListQuery(c, attributeListOptions)
+	if err != nil {
+		return err
+	}
+
+	attributes, total, err := h.attributeUsecase.GetAllAttributes(q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(attributes, total, q))
+}
+
+func (h *AttributeHandler) GetAttributeByID(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+
+	attribute, err := h.attributeUsecase.GetAttributeByID(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": attribute})
+}
+
+func (h *AttributeHandler) AddAttribute(c echo.Context, attribute *request.AttributeRequest) error {
+	created, err := h.attributeUsecase.AddAttribute(attribute)
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Attribute created successfully", "data": created})
+}
+
+func (h *AttributeHandler) PatchAttribute(c echo.Context, attribute *request.AttributePatchRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.attributeUsecase.UpdateAttribute(id, attribute)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Attribute updated successfully", "data": updated})
+}
+
+func (h *AttributeHandler) DeleteAttribute(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.attributeUsecase.DeleteAttribute(id); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+func (h *AttributeHandler) GetValuesByAttributeID(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+
+	q, err := pkg.ParseListQuery(c, attributeValueListOptions)
+	if err != nil {
+		return err
+	}
+
+	values, total, err := h.attributeUsecase.GetValuesByAttributeID(id, q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(values, total, q))
+}
+
+func (h *AttributeHandler) AddAttributeValue(c echo.Context, value *request.AttributeValueRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+
+	created, err := h.attributeUsecase.AddAttributeValue(id, value)
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Attribute value created successfully", "data": created})
+}
+
+func (h *AttributeHandler) PatchAttributeValue(c echo.Context, value *request.AttributeValuePatchRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+	valueID, err := pkg.ParamID(c, "valueId", pkg.AttributeValueNotFound)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.attributeUsecase.UpdateAttributeValue(id, valueID, value)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Attribute value updated successfully", "data": updated})
+}
+
+func (h *AttributeHandler) DeleteAttributeValue(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.AttributeNotFound)
+	if err != nil {
+		return err
+	}
+	valueID, err := pkg.ParamID(c, "valueId", pkg.AttributeValueNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.attributeUsecase.DeleteAttributeValue(id, valueID); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
@@ -1,14 +1,24 @@
 package handler
 
 import (
-	"errors"
 	"product-service/internal/pkg"
 	"product-service/internal/request"
 	"product-service/internal/usecase"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
 
+var categoryListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id", "name": "name", "position": "position", "created_at": "created_at"},
+	DefaultSort: "id:asc",
+	Filters: map[string]pkg.FilterField{
+		"name":      {Column: "name", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+		"parent_id": {Column: "parent_id", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn, pkg.OpIsNull}, Type: pkg.FilterInt},
+	},
+	AllowDeleted: true,
+}
+
 type CategoryHandler struct {
 	categoryUsecase *usecase.CategoryUsecase
 }
@@ -18,85 +28,234 @@ func NewCategoryHandler(categoryUsecase *usecase.CategoryUsecase) *CategoryHandl
 }
 
 func (h *CategoryHandler) GetAllCategories(c echo.Context) error {
-	categories, err := h.categoryUsecase.GetAllCategories()
+	q, err := pkg.ParseListQuery(c, categoryListOptions)
 	if err != nil {
-		return c.JSON(500, echo.Map{"message": "Failed to retrieve categories"})
+		return err
 	}
-	return c.JSON(200, echo.Map{"data": categories})
+
+	categories, total, err := h.categoryUsecase.GetAllCategories(q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(categories, total, q))
 }
 
 func (h *CategoryHandler) AddCategory(c echo.Context, category *request.CategoryRequest) error {
-	if err := h.categoryUsecase.AddCategory(category); err != nil {
-		switch {
-		case errors.Is(err, pkg.DuplicateEntry):
-			return c.JSON(409, echo.Map{"message": "Category name already exists"})
-		case errors.Is(err, pkg.ParentCategoryNotFound):
-			return c.JSON(404, echo.Map{"message": "Parent category not found"})
-		default:
-			return c.JSON(500, echo.Map{"message": "Failed to create category"})
-		}
+	created, err := h.categoryUsecase.AddCategory(category)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(201, echo.Map{"message": "Category created successfully", "data": category})
+	return c.JSON(201, echo.Map{"message": "Category created successfully", "data": created})
 }
 
 func (h *CategoryHandler) GetCategoryByID(c echo.Context) error {
 	id := c.Param("id")
-	category, err := h.categoryUsecase.GetCategoryByID(id)
+	if !pkg.IsNumericID(id) {
+		categoryID, slug, err := h.categoryUsecase.ResolveCategorySlug(id)
+		if err != nil {
+			return err
+		}
+		if slug != id {
+			return slugRedirect(c, "/categories", categoryID, slug)
+		}
+		id = pkg.UintToString(categoryID)
+	} else if _, ok := pkg.ParseID(id); !ok {
+		return pkg.CategoryNotFound
+	}
+
+	withBreadcrumbs := c.QueryParam("breadcrumbs") == "true"
+	category, err := h.categoryUsecase.GetCategoryByID(id, withBreadcrumbs)
 	if err != nil {
-		return c.JSON(500, echo.Map{"message": err.Error()})
+		return err
 	}
 	return c.JSON(200, category)
 }
 
+func (h *CategoryHandler) GetCategoryPath(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	path, err := h.categoryUsecase.GetCategoryPath(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": path})
+}
+
 func (h *CategoryHandler) PatchCategory(c echo.Context, category *request.CategoryPatchRequest) error {
-	id := c.Param("id")
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
 
 	updatedCategory, err := h.categoryUsecase.UpdateCategory(id, category)
 	if err != nil {
-		switch {
-		case errors.Is(err, pkg.CategoryNotFound):
-			return c.JSON(404, echo.Map{"message": "Category not found"})
-		case errors.Is(err, pkg.ParentCategoryNotFound):
-			return c.JSON(404, echo.Map{"message": "Parent category not found"})
-		case errors.Is(err, pkg.CategoryCannotBeItsOwnParent):
-			return c.JSON(400, echo.Map{"message": "Category cannot be its own parent"})
-		case errors.Is(err, pkg.DuplicateEntry):
-			return c.JSON(409, echo.Map{"message": "Category name already exists"})
-		case errors.Is(err, pkg.NoFieldsToUpdate):
-			return c.JSON(400, echo.Map{"message": "No fields provided to update"})
-		default:
-			return c.JSON(500, echo.Map{"message": "Failed to update category"})
-		}
+		return err
 	}
 
 	return c.JSON(200, echo.Map{"message": "Category updated successfully", "data": updatedCategory})
 }
 
 func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
-	id := c.Param("id")
-	if err := h.categoryUsecase.DeleteCategory(id); err != nil {
-		return c.JSON(500, echo.Map{"message": err.Error()})
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	cascade := c.QueryParam("cascade") == "true"
+	if err := h.categoryUsecase.DeleteCategory(id, cascade); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+// RestoreCategory undeletes a category; with ?cascade=true its deleted
+// descendants are restored too.
+func (h *CategoryHandler) RestoreCategory(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	cascade := c.QueryParam("cascade") == "true"
+	category, err := h.categoryUsecase.RestoreCategory(id, cascade)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Category restored successfully", "data": category})
+}
+
+func (h *CategoryHandler) PurgeCategory(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.categoryUsecase.PurgeCategory(id); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+func (h *CategoryHandler) MoveCategory(c echo.Context, move *request.CategoryMoveRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	category, err := h.categoryUsecase.MoveCategory(id, move)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Category moved successfully", "data": category})
+}
+
+// ReorderCategories orders the children of :id, or the top-level categories
+// when the route has no :id.
+func (h *CategoryHandler) ReorderCategories(c echo.Context, order *request.CategoryOrderRequest) error {
+	var parentID *uint
+	if id := c.Param("id"); id != "" {
+		parent, ok := pkg.ParseID(id)
+		if !ok {
+			return pkg.CategoryNotFound
+		}
+		parentID = &parent
+	}
+
+	categories, err := h.categoryUsecase.ReorderCategories(parentID, order)
+	if err != nil {
+		return err
 	}
-	return c.JSON(204, nil)
+	return c.JSON(200, echo.Map{"message": "Categories reordered successfully", "data": categories})
+}
+
+func (h *CategoryHandler) MergeCategory(c echo.Context, merge *request.CategoryMergeRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	category, err := h.categoryUsecase.MergeCategory(id, merge)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Category merged successfully", "data": category})
 }
 
 func (h *CategoryHandler) GetCategoryTree(c echo.Context) error {
-	categories, err := h.categoryUsecase.GetCategoryTree()
+	var rootID *uint
+	if v := c.QueryParam("root_id"); v != "" {
+		id, ok := pkg.ParseID(v)
+		if !ok {
+			return pkg.InvalidQueryParameter.Withf("root_id must be a category ID")
+		}
+		rootID = &id
+	}
+	maxDepth := -1
+	if v := c.QueryParam("max_depth"); v != "" {
+		depth, err := strconv.Atoi(v)
+		if err != nil || depth < 0 {
+			return pkg.InvalidQueryParameter.Withf("max_depth must be a non-negative integer")
+		}
+		maxDepth = depth
+	}
+
+	categories, err := h.categoryUsecase.GetCategoryTree(rootID, maxDepth)
 	if err != nil {
-		return c.JSON(500, echo.Map{"message": "Failed to retrieve category tree"})
+		return err
 	}
 	return c.JSON(200, echo.Map{"data": categories})
 }
 
 func (h *CategoryHandler) GetChildCategoriesByID(c echo.Context) error {
-	categories, err := h.categoryUsecase.GetChildCategoriesByID(c.Param("id"))
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
 	if err != nil {
-		return c.JSON(500, echo.Map{"message": "Failed to retrieve child categories"})
+		return err
 	}
-	return c.JSON(200, echo.Map{"data": categories})
+
+	q, err := pkg.ParseListQuery(c, categoryListOptions)
+	if err != nil {
+		return err
+	}
+
+	categories, total, err := h.categoryUsecase.GetChildCategoriesByID(id, q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(categories, total, q))
 }
 
 func (h *CategoryHandler) GetProductsByCategoryID(c echo.Context) error {
-	return nil
+	id, err := pkg.ParamID(c, "id", pkg.CategoryNotFound)
+	if err != nil {
+		return err
+	}
+
+	q, err := pkg.ParseListQuery(c, productListOptions)
+	if err != nil {
+		return err
+	}
+	includeDescendants := c.QueryParam("include_descendants") == "true"
+
+	withFacets, buckets, err := parseFacetQuery(c)
+	if err != nil {
+		return err
+	}
+
+	products, total, err := h.categoryUsecase.GetProductsByCategoryID(id, includeDescendants, q)
+	if err != nil {
+		return err
+	}
+	res := pkg.NewKeysetListResponse(products, total, q, productCursorKey)
+	if withFacets {
+		facets, err := h.categoryUsecase.GetCategoryProductFacets(id, includeDescendants, q, buckets)
+		if err != nil {
+			return err
+		}
+		res.Facets = facets
+	}
+	return c.JSON(200, res)
 }
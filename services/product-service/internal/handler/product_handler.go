@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"math"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"product-service/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// priceColumn is the decimal amount of a price, e.g. 19.99. Amounts are only
+// comparable within a currency, so price filters must come with a
+// price_currency filter.
+var priceColumn = pkg.MoneySQL("price")
+
+var productListOptions = pkg.ListOptions{
+	Sorts: map[string]string{
+		"id": "id", "name": "name", "price": priceColumn, "created_at": "created_at", "updated_at": "updated_at",
+	},
+	DefaultSort: "id:asc",
+	Filters: map[string]pkg.FilterField{
+		"name":        {Column: "name", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+		"category_id": {Column: "category_id", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}, Type: pkg.FilterInt},
+		"price": {Column: priceColumn, Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpGt, pkg.OpGte, pkg.OpLt, pkg.OpLte},
+			Type: pkg.FilterNumber, Requires: "price_currency"},
+		"price_currency": {Column: "price_currency", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}},
+		"created_by":     {Column: "created_by", Ops: []pkg.FilterOp{pkg.OpEq}},
+	},
+	Keyset:       true,
+	AllowDeleted: true,
+}
+
+// productCursorKey returns the value of a product sort field for keyset
+// cursors.
+func productCursorKey(p response.ProductResponse, field string) any {
+	switch field {
+	case "name":
+		return p.Name
+	case "price":
+		return p.Price.Decimal()
+	case "created_at":
+		return p.CreatedAt
+	case "updated_at":
+		return p.UpdatedAt
+	default:
+		return p.ID
+	}
+}
+
+var productSearchOptions = pkg.ListOptions{
+	Filters: map[string]pkg.FilterField{
+		"category_id": {Column: "p.category_id", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}, Type: pkg.FilterInt},
+		"price": {Column: pkg.MoneySQL("p.price"), Ops: []pkg.FilterOp{pkg.OpGt, pkg.OpGte, pkg.OpLt, pkg.OpLte},
+			Type: pkg.FilterNumber, Requires: "price_currency"},
+		"price_currency": {Column: "p.price_currency", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}},
+	},
+}
+
+// defaultPriceBuckets are the price range boundaries used for facets when
+// the request does not pass ?price_buckets=.
+var defaultPriceBuckets = []float64{25, 50, 100, 250, 500}
+
+const maxPriceBuckets = 20
+
+type ProductHandler struct {
+	productUsecase *usecase.ProductUsecase
+}
+
+func NewProductHandler(productUsecase *usecase.ProductUsecase) *ProductHandler {
+	return &ProductHandler{productUsecase: productUsecase}
+}
+
+func (h *ProductHandler) GetAllProducts(c echo.Context) error {
+	q, err := pkg.ParseListQuery(c, productListOptions)
+	if err != nil {
+		return err
+	}
+
+	withFacets, buckets, err := parseFacetQuery(c)
+	if err != nil {
+		return err
+	}
+
+	products, total, err := h.productUsecase.GetAllProducts(q)
+	if err != nil {
+		return err
+	}
+	res := pkg.NewKeysetListResponse(products, total, q, productCursorKey)
+	if withFacets {
+		facets, err := h.productUsecase.GetProductFacets(q, buckets)
+		if err != nil {
+			return err
+		}
+		res.Facets = facets
+	}
+	return c.JSON(200, res)
+}
+
+func (h *ProductHandler) SearchProducts(c echo.Context) error {
+	q, err := pkg.ParseListQuery(c, productSearchOptions)
+	if err != nil {
+		return err
+	}
+
+	withFacets, buckets, err := parseFacetQuery(c)
+	if err != nil {
+		return err
+	}
+
+	term := c.QueryParam("q")
+	results, total, err := h.productUsecase.SearchProducts(term, q)
+	if err != nil {
+		return err
+	}
+	res := pkg.NewListResponse(results, total, q)
+	if withFacets {
+		facets, err := h.productUsecase.GetSearchFacets(term, q, buckets)
+		if err != nil {
+			return err
+		}
+		res.Facets = facets
+	}
+	return c.JSON(200, res)
+}
+
+func (h *ProductHandler) GetProductByID(c echo.Context) error {
+	id := c.Param("id")
+	if !pkg.IsNumericID(id) {
+		productID, slug, err := h.productUsecase.ResolveProductSlug(id)
+		if err != nil {
+			return err
+		}
+		if slug != id {
+			return slugRedirect(c, "/products", productID, slug)
+		}
+		id = pkg.UintToString(productID)
+	} else if _, ok := pkg.ParseID(id); !ok {
+		return pkg.ProductNotFound
+	}
+
+	product, err := h.productUsecase.GetProductByID(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": product})
+}
+
+func (h *ProductHandler) AddProduct(c echo.Context, product *request.ProductRequest) error {
+	created, err := h.productUsecase.AddProduct(product)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(201, echo.Map{"message": "Product created successfully", "data": created})
+}
+
+func (h *ProductHandler) PatchProduct(c echo.Context, product *request.ProductPatchRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	updatedProduct, err := h.productUsecase.UpdateProduct(id, product)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, echo.Map{"message": "Product updated successfully", "data": updatedProduct})
+}
+
+func (h *ProductHandler) DeleteProduct(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.productUsecase.DeleteProduct(id); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+func (h *ProductHandler) RestoreProduct(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	product, err := h.productUsecase.RestoreProduct(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Product restored successfully", "data": product})
+}
+
+func (h *ProductHandler) PurgeProduct(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.productUsecase.PurgeProduct(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+// parseFacetQuery reads ?facets=true and the optional ?price_buckets=25,50,100
+// list of ascending price range boundaries.
+func parseFacetQuery(c echo.Context) (bool, []float64, error) {
+	withFacets := false
+	if v := c.QueryParam("facets"); v != "" {
+		var err error
+		if withFacets, err = strconv.ParseBool(v); err != nil {
+			return false, nil, pkg.InvalidQueryParameter.Withf("facets must be true or false")
+		}
+	}
+
+	v := c.QueryParam("price_buckets")
+	if v == "" {
+		return withFacets, defaultPriceBuckets, nil
+	}
+	parts := strings.Split(v, ",")
+	if len(parts) > maxPriceBuckets {
+		return false, nil, pkg.InvalidQueryParameter.Withf("at most %d price buckets are allowed", maxPriceBuckets)
+	}
+	buckets := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		b, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || b < 0 || math.IsInf(b, 0) || math.IsNaN(b) || (len(buckets) > 0 && b <= buckets[len(buckets)-1]) {
+			return false, nil, pkg.InvalidQueryParameter.Withf("price_buckets must be ascending non-negative numbers")
+		}
+		buckets = append(buckets, b)
+	}
+	return withFacets, buckets, nil
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/usecase"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+var productImageListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id", "position": "position", "created_at": "created_at"},
+	DefaultSort: "position:asc",
+	Filters: map[string]pkg.FilterField{
+		"is_primary": {Column: "is_primary", Ops: []pkg.FilterOp{pkg.OpEq}, Type: pkg.FilterBool},
+	},
+}
+
+type ProductImageHandler struct {
+	imageUsecase *usecase.ProductImageUsecase
+}
+
+func NewProductImageHandler(imageUsecase *usecase.ProductImageUsecase) *ProductImageHandler {
+	return &ProductImageHandler{imageUsecase: imageUsecase}
+}
+
+func (h *ProductImageHandler) GetImagesByProductID(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	q, err := pkg.ParseListQuery(c, productImageListOptions)
+	if err != nil {
+		return err
+	}
+
+	images, total, err := h.imageUsecase.GetImagesByProductID(id, q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(images, total, q))
+}
+
+func (h *ProductImageHandler) UploadImage(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return pkg.InvalidRequestBody.Withf("File is required")
+	}
+	if fileHeader.Size > usecase.MaxImageSize {
+		return pkg.ImageTooLarge
+	}
+
+	var position *int
+	if p := c.FormValue("position"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return pkg.InvalidRequestBody.Withf("Position must be a non-negative integer")
+		}
+		position = &v
+	}
+	isPrimary := c.FormValue("is_primary") == "true"
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return pkg.InvalidRequestBody.Withf("Failed to read uploaded file").Wrap(err)
+	}
+	defer file.Close()
+
+	image, err := h.imageUsecase.UploadImage(c.Request().Context(), id, file, isPrimary, position)
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Product image uploaded successfully", "data": image})
+}
+
+func (h *ProductImageHandler) PatchImage(c echo.Context, patch *request.ProductImagePatchRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	imageID, err := pkg.ParamID(c, "imageId", pkg.ProductImageNotFound)
+	if err != nil {
+		return err
+	}
+
+	image, err := h.imageUsecase.UpdateImage(id, imageID, patch)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Product image updated successfully", "data": image})
+}
+
+func (h *ProductImageHandler) ReorderImages(c echo.Context, order *request.ProductImageOrderRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	images, err := h.imageUsecase.ReorderImages(id, order)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Product images reordered successfully", "data": images})
+}
+
+func (h *ProductImageHandler) RegenerateRenditions(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	imageID, err := pkg.ParamID(c, "imageId", pkg.ProductImageNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.imageUsecase.RegenerateRenditions(id, imageID); err != nil {
+		return err
+	}
+	return c.JSON(202, echo.Map{"message": "Image renditions queued"})
+}
+
+func (h *ProductImageHandler) DeleteImage(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	imageID, err := pkg.ParamID(c, "imageId", pkg.ProductImageNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.imageUsecase.DeleteImage(c.Request().Context(), id, imageID); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
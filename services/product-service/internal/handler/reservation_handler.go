@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"product-service/internal/request"
+	"product-service/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReservationHandler struct {
+	reservationUsecase *usecase.ReservationUsecase
+}
+
+func NewReservationHandler(reservationUsecase *usecase.ReservationUsecase) *ReservationHandler {
+	return &ReservationHandler{reservationUsecase: reservationUsecase}
+}
+
+func (h *ReservationHandler) Reserve(c echo.Context, reservation *request.ReservationRequest) error {
+	reservations, err := h.reservationUsecase.Reserve(reservation, actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Stock reserved successfully", "data": reservations})
+}
+
+func (h *ReservationHandler) GetReservations(c echo.Context) error {
+	reservations, err := h.reservationUsecase.GetReservations(c.Param("orderRef"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": reservations})
+}
+
+func (h *ReservationHandler) Commit(c echo.Context) error {
+	reservations, err := h.reservationUsecase.Commit(c.Param("orderRef"), actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Reservations committed successfully", "data": reservations})
+}
+
+func (h *ReservationHandler) Release(c echo.Context) error {
+	reservations, err := h.reservationUsecase.Release(c.Param("orderRef"), actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Reservations released successfully", "data": reservations})
+}
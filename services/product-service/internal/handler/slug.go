@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"product-service/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// slugRedirect answers a request for an outdated slug with a 301 pointing at
+// the resource's current slug, keeping the query string.
+func slugRedirect(c echo.Context, basePath string, id uint, slug string) error {
+	location := basePath + "/" + slug
+	if qs := c.QueryString(); qs != "" {
+		location += "?" + qs
+	}
+	c.Response().Header().Set(echo.HeaderLocation, location)
+	return c.JSON(301, echo.Map{
+		"message": "Resource has moved",
+		"data":    response.SlugRedirectResponse{ID: id, Slug: slug, Location: location},
+	})
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StockSubscriptionHandler struct {
+	subscriptionUsecase *usecase.StockSubscriptionUsecase
+}
+
+func NewStockSubscriptionHandler(subscriptionUsecase *usecase.StockSubscriptionUsecase) *StockSubscriptionHandler {
+	return &StockSubscriptionHandler{subscriptionUsecase: subscriptionUsecase}
+}
+
+func (h *StockSubscriptionHandler) Subscribe(c echo.Context, subscription *request.StockSubscriptionRequest) error {
+	created, err := h.subscriptionUsecase.Subscribe(subscription)
+	if err != nil {
+		if errors.Is(err, pkg.DuplicateEntry) {
+			return pkg.DuplicateEntry.Withf("Already subscribed to this variant").Wrap(err)
+		}
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Subscribed successfully", "data": created})
+}
+
+func (h *StockSubscriptionHandler) GetSubscription(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.StockSubscriptionNotFound)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := h.subscriptionUsecase.GetSubscription(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": subscription})
+}
+
+func (h *StockSubscriptionHandler) Unsubscribe(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.StockSubscriptionNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.subscriptionUsecase.Unsubscribe(id); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
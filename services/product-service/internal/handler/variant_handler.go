@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"product-service/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+var variantListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id", "sku": "sku", "price": priceColumn, "stock": "stock"},
+	DefaultSort: "id:asc",
+	Filters: map[string]pkg.FilterField{
+		"sku": {Column: "sku", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+		"price": {Column: priceColumn, Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpGt, pkg.OpGte, pkg.OpLt, pkg.OpLte},
+			Type: pkg.FilterNumber, Requires: "price_currency"},
+		"price_currency": {Column: "price_currency", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}},
+		"stock":          {Column: "stock", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpGt, pkg.OpGte, pkg.OpLt, pkg.OpLte}, Type: pkg.FilterInt},
+	},
+	Keyset:       true,
+	AllowDeleted: true,
+}
+
+// variantCursorKey returns the value of a variant sort field for keyset
+// cursors.
+func variantCursorKey(v response.VariantResponse, field string) any {
+	switch field {
+	case "sku":
+		return v.SKU
+	case "price":
+		return v.Price.Decimal()
+	case "stock":
+		return v.Stock
+	default:
+		return v.ID
+	}
+}
+
+var stockHistoryListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id"},
+	DefaultSort: "id:desc",
+	Filters: map[string]pkg.FilterField{
+		"type":         {Column: "type", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}},
+		"warehouse_id": {Column: "warehouse_id", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}, Type: pkg.FilterInt},
+		"reference":    {Column: "reference", Ops: []pkg.FilterOp{pkg.OpEq}},
+		"actor":        {Column: "actor", Ops: []pkg.FilterOp{pkg.OpEq}},
+		"created_at":   {Column: "created_at", Ops: []pkg.FilterOp{pkg.OpGte, pkg.OpLt}, Type: pkg.FilterTime},
+	},
+	Keyset: true,
+}
+
+// stockMovementCursorKey returns the ID of a movement, the only sort key of
+// the stock history.
+func stockMovementCursorKey(m response.StockMovementResponse, field string) any {
+	return m.ID
+}
+
+type VariantHandler struct {
+	variantUsecase *usecase.VariantUsecase
+}
+
+func NewVariantHandler(variantUsecase *usecase.VariantUsecase) *VariantHandler {
+	return &VariantHandler{variantUsecase: variantUsecase}
+}
+
+func (h *VariantHandler) GetVariantsByProductID(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	q, err := pkg.ParseListQuery(c, variantListOptions)
+	if err != nil {
+		return err
+	}
+
+	variants, total, err := h.variantUsecase.GetVariantsByProductID(id, q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewKeysetListResponse(variants, total, q, variantCursorKey))
+}
+
+func (h *VariantHandler) GetVariantByID(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	variant, err := h.variantUsecase.GetVariantByID(id, variantID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": variant})
+}
+
+func (h *VariantHandler) AddVariant(c echo.Context, variant *request.VariantRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	created, err := h.variantUsecase.AddVariant(id, variant, actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Variant created successfully", "data": created})
+}
+
+func (h *VariantHandler) PatchVariant(c echo.Context, variant *request.VariantPatchRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.variantUsecase.UpdateVariant(id, variantID, variant, actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Variant updated successfully", "data": updated})
+}
+
+func (h *VariantHandler) DeleteVariant(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.variantUsecase.DeleteVariant(id, variantID); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+func (h *VariantHandler) GetVariantStock(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	stock, err := h.variantUsecase.GetVariantStock(id, variantID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": stock})
+}
+
+func (h *VariantHandler) GetStockHistory(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	q, err := pkg.ParseListQuery(c, stockHistoryListOptions)
+	if err != nil {
+		return err
+	}
+
+	movements, total, err := h.variantUsecase.GetStockHistory(id, q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewKeysetListResponse(movements, total, q, stockMovementCursorKey))
+}
+
+func (h *VariantHandler) AddStockMovement(c echo.Context, movement *request.StockMovementRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	created, err := h.variantUsecase.AddStockMovement(id, movement, actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Stock movement recorded successfully", "data": created})
+}
+
+func (h *VariantHandler) RestoreVariant(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	variant, err := h.variantUsecase.RestoreVariant(id, variantID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Variant restored successfully", "data": variant})
+}
+
+func (h *VariantHandler) PurgeVariant(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.variantUsecase.PurgeVariant(id, variantID); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+func (h *VariantHandler) GenerateVariants(c echo.Context, matrix *request.VariantMatrixRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.ProductNotFound)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.variantUsecase.GenerateVariants(id, matrix, actor(c))
+	if err != nil {
+		return err
+	}
+	if matrix.DryRun {
+		return c.JSON(200, echo.Map{"message": "Variant matrix preview", "data": result})
+	}
+	return c.JSON(201, echo.Map{"message": "Variants generated successfully", "data": result})
+}
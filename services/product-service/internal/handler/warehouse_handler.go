@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+var warehouseListOptions = pkg.ListOptions{
+	Sorts:       map[string]string{"id": "id", "code": "code", "name": "name", "priority": "priority"},
+	DefaultSort: "priority:asc",
+	Filters: map[string]pkg.FilterField{
+		"code": {Column: "code", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}},
+		"name": {Column: "name", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+	},
+}
+
+var warehouseStockListOptions = pkg.ListOptions{
+	Sorts: map[string]string{
+		"id":        "s.id",
+		"sku":       "v.sku",
+		"quantity":  "s.quantity",
+		"available": "s.quantity - s.reserved",
+	},
+	DefaultSort: "sku:asc",
+	Filters: map[string]pkg.FilterField{
+		"variant_id": {Column: "s.variant_id", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpIn}, Type: pkg.FilterInt},
+		"sku":        {Column: "v.sku", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpContains}},
+		"quantity":   {Column: "s.quantity", Ops: []pkg.FilterOp{pkg.OpEq, pkg.OpGt, pkg.OpGte, pkg.OpLt, pkg.OpLte}, Type: pkg.FilterInt},
+	},
+}
+
+type WarehouseHandler struct {
+	warehouseUsecase *usecase.WarehouseUsecase
+}
+
+func NewWarehouseHandler(warehouseUsecase *usecase.WarehouseUsecase) *WarehouseHandler {
+	return &WarehouseHandler{warehouseUsecase: warehouseUsecase}
+}
+
+func (h *WarehouseHandler) GetAllWarehouses(c echo.Context) error {
+	q, err := pkg.ParseListQuery(c, warehouseListOptions)
+	if err != nil {
+		return err
+	}
+
+	warehouses, total, err := h.warehouseUsecase.GetAllWarehouses(q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(warehouses, total, q))
+}
+
+func (h *WarehouseHandler) GetWarehouseByID(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.WarehouseNotFound)
+	if err != nil {
+		return err
+	}
+
+	warehouse, err := h.warehouseUsecase.GetWarehouseByID(id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"data": warehouse})
+}
+
+func (h *WarehouseHandler) AddWarehouse(c echo.Context, warehouse *request.WarehouseRequest) error {
+	created, err := h.warehouseUsecase.AddWarehouse(warehouse)
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Warehouse created successfully", "data": created})
+}
+
+func (h *WarehouseHandler) PatchWarehouse(c echo.Context, warehouse *request.WarehousePatchRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.WarehouseNotFound)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.warehouseUsecase.UpdateWarehouse(id, warehouse)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Warehouse updated successfully", "data": updated})
+}
+
+func (h *WarehouseHandler) DeleteWarehouse(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.WarehouseNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := h.warehouseUsecase.DeleteWarehouse(id); err != nil {
+		return err
+	}
+	return c.NoContent(204)
+}
+
+func (h *WarehouseHandler) GetWarehouseStock(c echo.Context) error {
+	id, err := pkg.ParamID(c, "id", pkg.WarehouseNotFound)
+	if err != nil {
+		return err
+	}
+
+	q, err := pkg.ParseListQuery(c, warehouseStockListOptions)
+	if err != nil {
+		return err
+	}
+
+	stock, total, err := h.warehouseUsecase.GetWarehouseStock(id, q)
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, pkg.NewListResponse(stock, total, q))
+}
+
+func (h *WarehouseHandler) SetWarehouseStock(c echo.Context, stock *request.WarehouseStockRequest) error {
+	id, err := pkg.ParamID(c, "id", pkg.WarehouseNotFound)
+	if err != nil {
+		return err
+	}
+	variantID, err := pkg.ParamID(c, "variantId", pkg.VariantNotFound)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.warehouseUsecase.SetWarehouseStock(id, variantID, stock, actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(200, echo.Map{"message": "Warehouse stock updated successfully", "data": updated})
+}
+
+func (h *WarehouseHandler) TransferStock(c echo.Context, transfer *request.StockTransferRequest) error {
+	movements, err := h.warehouseUsecase.TransferStock(transfer, actor(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(201, echo.Map{"message": "Stock transferred successfully", "data": movements})
+}
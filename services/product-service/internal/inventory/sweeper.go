@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"context"
+	"product-service/internal/repository"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// sweepBatch is how many expired reservations are released per transaction.
+const sweepBatch = 100
+
+// ReservationSweeper periodically releases reservations whose TTL has passed
+// so the stock they hold becomes available again.
+type ReservationSweeper struct {
+	reservationRepo *repository.ReservationRepository
+	interval        time.Duration
+}
+
+func NewReservationSweeper(reservationRepo *repository.ReservationRepository, interval time.Duration) *ReservationSweeper {
+	return &ReservationSweeper{reservationRepo: reservationRepo, interval: interval}
+}
+
+// Start runs the sweeper until ctx is cancelled.
+func (s *ReservationSweeper) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+func (s *ReservationSweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep releases expired reservations batch by batch until none are left.
+func (s *ReservationSweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		released, err := s.reservationRepo.ReleaseExpired(sweepBatch)
+		if err != nil {
+			log.Errorf("Failed to release expired reservations: %v", err)
+			return
+		}
+		if released > 0 {
+			log.Infof("Released %d expired reservations", released)
+		}
+		if released < sweepBatch {
+			return
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 )
 
 func RegisterBasicMiddleware(e *echo.Echo) {
+	e.HTTPErrorHandler = ProblemErrorHandler
+
 	e.Pre(middleware.RemoveTrailingSlash())
 
 	e.Use(middleware.Recover())
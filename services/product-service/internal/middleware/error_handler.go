@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"product-service/internal/pkg"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const mimeProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body, extended with the
+// application error code and, for validation failures, per-field errors.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	Errors   any    `json:"errors,omitempty"`
+}
+
+// ProblemErrorHandler renders every error returned by a handler as
+// application/problem+json. Server errors are logged with their cause and
+// shown to the client only as a generic message.
+func ProblemErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := httpError(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(appErr.Status),
+		Status:   appErr.Status,
+		Detail:   appErr.Message,
+		Instance: c.Request().URL.Path,
+		Code:     appErr.Code,
+		Errors:   appErr.Details,
+	}
+
+	var writeErr error
+	if c.Request().Method == http.MethodHead {
+		writeErr = c.NoContent(appErr.Status)
+	} else {
+		c.Response().Header().Set(echo.HeaderContentType, mimeProblemJSON)
+		writeErr = c.JSON(appErr.Status, problem)
+	}
+	if writeErr != nil {
+		c.Logger().Error(writeErr)
+	}
+}
+
+// httpError is pkg.AsError that also understands the errors echo itself
+// raises, such as unknown routes and oversized bodies.
+func httpError(err error) *pkg.Error {
+	var appErr *pkg.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code := strings.ReplaceAll(strings.ToLower(http.StatusText(he.Code)), " ", "_")
+		return pkg.NewError(he.Code, code, fmt.Sprint(he.Message)).Wrap(err)
+	}
+	return pkg.AsError(err)
+}
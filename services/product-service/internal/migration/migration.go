@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// statements holds schema changes GORM tags cannot express. Each one must be
+// idempotent since they run on every start.
+var statements = []string{
+	// Full-text search: generated tsvector columns with GIN indexes. Product
+	// and category text is stemmed as English, SKUs are indexed as-is.
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+	`ALTER TABLE categories ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('english', coalesce(name, ''))) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_categories_search_vector ON categories USING GIN (search_vector)`,
+	`ALTER TABLE variants ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('simple', coalesce(sku, ''))) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_variants_search_vector ON variants USING GIN (search_vector)`,
+	// Materialized category paths are matched by prefix.
+	`CREATE INDEX IF NOT EXISTS idx_categories_path ON categories (path text_pattern_ops)`,
+	// Attribute names were unique across deleted rows as well; replaced by
+	// idx_attributes_name_live.
+	`ALTER TABLE attributes DROP CONSTRAINT IF EXISTS uni_attributes_name`,
+}
+
+// Run brings the schema up to date: GORM auto-migration for the entities and
+// the raw statements above, then backfills data new columns depend on. Prices
+// stored before they carried a currency are taken to be in currency.
+func Run(db *gorm.DB, currency string) error {
+	// Stock that existed before the ledger gets an opening balance once,
+	// when its table is created.
+	introducesLedger := !db.Migrator().HasTable(&entity.StockMovement{})
+
+	for _, table := range []string{"products", "variants"} {
+		if err := convertPrices(db, table, currency); err != nil {
+			return err
+		}
+	}
+	if err := db.AutoMigrate(
+		&entity.Category{},
+		&entity.Product{},
+		&entity.Attribute{},
+		&entity.AttributeValue{},
+		&entity.Variant{},
+		&entity.ProductImage{},
+		&entity.ProductImageRendition{},
+		&entity.SlugHistory{},
+		&entity.StockReservation{},
+		&entity.Warehouse{},
+		&entity.WarehouseStock{},
+		&entity.StockMovement{},
+		&entity.StockSubscription{},
+		&entity.StockNotification{},
+	); err != nil {
+		return err
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	if err := repository.AssignMissingSlugs(db); err != nil {
+		return err
+	}
+	if err := repository.BackfillWarehouseStock(db); err != nil {
+		return err
+	}
+	if introducesLedger {
+		if err := repository.BackfillStockLedger(db); err != nil {
+			return err
+		}
+	}
+	missing, err := repository.HasMissingCategoryPaths(db)
+	if err != nil || !missing {
+		return err
+	}
+	_, err = repository.RebuildCategoryPaths(db)
+	return err
+}
+
+// convertPrices replaces the float price column of table with the money
+// columns price_amount, in minor units of currency, and price_currency.
+func convertPrices(db *gorm.DB, table, currency string) error {
+	if !db.Migrator().HasColumn(table, "price") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE ` + table + `
+			ADD COLUMN IF NOT EXISTS price_amount bigint,
+			ADD COLUMN IF NOT EXISTS price_currency char(3)`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`UPDATE `+table+` SET price_amount = round(coalesce(price, 0)::numeric * ?), price_currency = ?`,
+			pkg.MinorUnitFactor(currency), currency).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`ALTER TABLE ` + table + ` DROP COLUMN price`).Error
+	})
+}
@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"context"
+	"product-service/internal/entity"
+	"product-service/internal/repository"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// dispatchBatch is how many notifications are delivered per transaction.
+const dispatchBatch = 50
+
+// Dispatcher periodically hands queued stock notifications to a Sender.
+type Dispatcher struct {
+	notificationRepo *repository.NotificationRepository
+	sender           Sender
+	interval         time.Duration
+}
+
+func NewDispatcher(notificationRepo *repository.NotificationRepository, sender Sender,
+	interval time.Duration) *Dispatcher {
+	return &Dispatcher{notificationRepo: notificationRepo, sender: sender, interval: interval}
+}
+
+// Start runs the dispatcher until ctx is cancelled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.run(ctx)
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch delivers due notifications batch by batch until none are left.
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		handled, err := d.notificationRepo.DeliverPending(dispatchBatch, func(n entity.StockNotification) error {
+			return d.sender.Send(ctx, Message{
+				Type:      n.Type,
+				Recipient: n.Recipient,
+				VariantID: n.VariantID,
+				SKU:       n.SKU,
+				Available: n.Available,
+				Threshold: n.Threshold,
+			})
+		})
+		if err != nil {
+			log.Errorf("Failed to deliver stock notifications: %v", err)
+			return
+		}
+		if handled < dispatchBatch {
+			return
+		}
+	}
+}
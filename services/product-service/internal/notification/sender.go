@@ -0,0 +1,38 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/labstack/gommon/log"
+)
+
+// Message is a stock notification ready to be delivered. Recipient is empty
+// for low-stock alerts, which go wherever the sender routes internal alerts.
+type Message struct {
+	Type      string
+	Recipient string
+	VariantID uint
+	SKU       string
+	Available int
+	Threshold *int
+}
+
+// Sender delivers notifications. A returned error makes the dispatcher retry
+// the message later.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes notifications to the log instead of delivering them, for
+// local runs.
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	log.Infof("Notification %s: sku=%s variant=%d available=%d recipient=%q",
+		msg.Type, msg.SKU, msg.VariantID, msg.Available, msg.Recipient)
+	return nil
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	cursorMu     sync.RWMutex
+	cursorSecret []byte
+)
+
+// cursorPayload is what an opaque cursor token carries. Offset cursors only
+// set O; keyset cursors record the sort they were issued for and the sort key
+// values of the last row returned.
+type cursorPayload struct {
+	O      int      `json:"o,omitempty"`
+	Sort   string   `json:"s,omitempty"`
+	Values []string `json:"v,omitempty"`
+}
+
+// SetCursorSecret sets the HMAC key used to sign cursor tokens. Every
+// instance serving the same API must share it; an empty secret is replaced by
+// a random one, which invalidates cursors on restart.
+func SetCursorSecret(secret []byte) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	}
+	cursorMu.Lock()
+	cursorSecret = secret
+	cursorMu.Unlock()
+}
+
+func getCursorSecret() []byte {
+	cursorMu.RLock()
+	secret := cursorSecret
+	cursorMu.RUnlock()
+	if secret == nil {
+		SetCursorSecret(nil)
+		return getCursorSecret()
+	}
+	return secret
+}
+
+func encodeCursor(p cursorPayload) string {
+	raw, _ := json.Marshal(p)
+	mac := hmac.New(sha256.New, getCursorSecret())
+	mac.Write(raw)
+	return base64.RawURLEncoding.EncodeToString(raw) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func decodeCursor(token string) (*cursorPayload, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errors.New("malformed cursor")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, err
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, getCursorSecret())
+	mac.Write(raw)
+	if !hmac.Equal(gotMAC, mac.Sum(nil)) {
+		return nil, errors.New("cursor signature mismatch")
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+	if p.O < 0 {
+		return nil, errors.New("invalid offset")
+	}
+	return &p, nil
+}
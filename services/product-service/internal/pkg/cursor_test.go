@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	SetCursorSecret([]byte("test-secret"))
+
+	tests := []cursorPayload{
+		{O: 40},
+		{Sort: "price:desc,id:desc", Values: []string{"19.99", "42"}},
+		{Sort: "id:asc", Values: []string{"7"}},
+	}
+	for _, p := range tests {
+		got, err := decodeCursor(encodeCursor(p))
+		if err != nil {
+			t.Fatalf("decodeCursor(encodeCursor(%+v)): %v", p, err)
+		}
+		if !reflect.DeepEqual(*got, p) {
+			t.Errorf("round trip of %+v = %+v", p, *got)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	SetCursorSecret([]byte("test-secret"))
+	valid := encodeCursor(cursorPayload{O: 20})
+	body, sig, _ := strings.Cut(valid, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"o":1000}`)) + "." + sig
+
+	SetCursorSecret([]byte("other-secret"))
+	otherSecret := encodeCursor(cursorPayload{O: 20})
+	SetCursorSecret([]byte("test-secret"))
+
+	negative := encodeCursor(cursorPayload{O: -5})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", body},
+		{"bad body encoding", "!!!." + sig},
+		{"bad signature encoding", body + ".!!!"},
+		{"truncated signature", body + "." + sig[:10]},
+		{"forged body", forged},
+		{"other secret", otherSecret},
+		{"negative offset", negative},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := decodeCursor(tt.token); err == nil {
+				t.Errorf("decodeCursor(%q) = %+v, want error", tt.token, p)
+			}
+		})
+	}
+}
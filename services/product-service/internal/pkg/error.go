@@ -1,12 +1,184 @@
 package pkg
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Error is an application error carrying a stable machine-readable code, the
+// HTTP status it maps to and a message that is safe to show to API clients.
+// Errors with the same code match under errors.Is, so a sentinel with a more
+// specific message still matches the sentinel it was derived from.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	// Details is rendered as an "errors" member of the problem body, e.g.
+	// the per-field validation errors.
+	Details any
+
+	cause error
+}
+
+func NewError(status int, code, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Withf returns a copy of e with a more specific message.
+func (e *Error) Withf(format string, args ...any) *Error {
+	clone := *e
+	clone.Message = fmt.Sprintf(format, args...)
+	return &clone
+}
+
+// WithDetails returns a copy of e carrying details for the problem body.
+func (e *Error) WithDetails(details any) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// Wrap returns a copy of e that records cause for logging. The cause is
+// never shown to clients.
+func (e *Error) Wrap(cause error) *Error {
+	clone := *e
+	clone.cause = cause
+	return &clone
+}
+
+// AsError maps any error returned by a handler to an *Error: application
+// errors pass through, missing records become NotFound, unique violations
+// DuplicateEntry and everything else InternalError wrapping the original.
+func AsError(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound.Wrap(err)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return DuplicateEntry.Wrap(err)
+	}
+	return InternalError.Wrap(err)
+}
 
 var (
-	ParentCategoryNotFound       = errors.New("parent category not found")
-	CategoryNotFound             = errors.New("category not found")
-	CategoryCannotBeItsOwnParent = errors.New("category cannot be its own parent")
-	CategoryHasChildren          = errors.New("category has child categories and cannot be deleted")
-	DuplicateEntry               = errors.New("Duplicated entry found.")
-	NoFieldsToUpdate             = errors.New("no fields provided to update")
+	InternalError      = NewError(http.StatusInternalServerError, "internal_error", "Internal server error")
+	NotFound           = NewError(http.StatusNotFound, "not_found", "Resource not found")
+	InvalidRequestBody = NewError(http.StatusBadRequest, "invalid_request_body", "Invalid request body")
+	ValidationFailed   = NewError(http.StatusBadRequest, "validation_failed", "Request validation failed")
+
+	ParentCategoryNotFound = NewError(http.StatusNotFound, "parent_category_not_found",
+		"Parent category not found")
+	CategoryNotFound             = NewError(http.StatusNotFound, "category_not_found", "Category not found")
+	CategoryCannotBeItsOwnParent = NewError(http.StatusBadRequest, "category_own_parent",
+		"Category cannot be its own parent")
+	CategoryHasChildren = NewError(http.StatusConflict, "category_has_children",
+		"Category has child categories; delete with cascade=true to remove them")
+	CategoryCycle = NewError(http.StatusConflict, "category_cycle",
+		"Category cannot be moved under one of its own descendants")
+	CategoryHasProducts = NewError(http.StatusConflict, "category_has_products",
+		"Category or one of its descendants still has products")
+	TargetCategoryNotFound = NewError(http.StatusNotFound, "target_category_not_found",
+		"Target category not found")
+	InvalidMergeTarget = NewError(http.StatusConflict, "invalid_merge_target",
+		"Category cannot be merged into itself or one of its descendants")
+	InvalidCategoryOrder = NewError(http.StatusBadRequest, "invalid_category_order",
+		"Category order must list every sibling category exactly once")
+	CategoryParentDeleted = NewError(http.StatusConflict, "category_parent_deleted",
+		"Parent category is deleted; restore it first")
+	ProductCategoryDeleted = NewError(http.StatusConflict, "product_category_deleted",
+		"Product category is deleted; restore it first")
+	ProductHasReservations = NewError(http.StatusConflict, "product_has_reservations",
+		"Product has variants with active reservations; commit or release them first")
+	RecordNotDeleted = NewError(http.StatusConflict, "record_not_deleted",
+		"Only deleted records can be purged")
+	DuplicateEntry        = NewError(http.StatusConflict, "duplicate_entry", "Resource already exists")
+	NoFieldsToUpdate      = NewError(http.StatusBadRequest, "no_fields_to_update", "No fields provided to update")
+	InvalidQueryParameter = NewError(http.StatusBadRequest, "invalid_query_parameter", "Invalid query parameter")
+	ProductNotFound       = NewError(http.StatusNotFound, "product_not_found", "Product not found")
+	EmptySearchQuery      = NewError(http.StatusBadRequest, "empty_search_query",
+		"Search query must contain at least one word")
+	VariantNotFound        = NewError(http.StatusNotFound, "variant_not_found", "Variant not found")
+	AttributeValueNotFound = NewError(http.StatusNotFound, "attribute_value_not_found",
+		"Attribute value not found")
+	DuplicateVariantAttributes = NewError(http.StatusConflict, "duplicate_variant_attributes",
+		"Another variant of this product has the same attribute values")
+	VariantAttributeConflict = NewError(http.StatusBadRequest, "variant_attribute_conflict",
+		"Variant cannot have more than one value for the same attribute")
+	AttributeNotFound = NewError(http.StatusNotFound, "attribute_not_found", "Attribute not found")
+	AttributeInUse    = NewError(http.StatusConflict, "attribute_in_use",
+		"Attribute has values used by variants and cannot be deleted")
+	AttributeValueInUse = NewError(http.StatusConflict, "attribute_value_in_use",
+		"Attribute value is used by variants and cannot be deleted")
+	InvalidAttributeValue = NewError(http.StatusBadRequest, "invalid_attribute_value",
+		"Attribute value does not match the attribute type")
+	AttributeTypeConflict = NewError(http.StatusConflict, "attribute_type_conflict",
+		"Existing attribute values do not match the new attribute type")
+	ProductImageNotFound = NewError(http.StatusNotFound, "product_image_not_found", "Product image not found")
+	UnsupportedImageType = NewError(http.StatusUnsupportedMediaType, "unsupported_image_type",
+		"Unsupported image type")
+	ImageTooLarge = NewError(http.StatusRequestEntityTooLarge, "image_too_large",
+		"Image exceeds the maximum upload size")
+	PrimaryImageRequired = NewError(http.StatusBadRequest, "primary_image_required",
+		"Product must keep a primary image; mark another image as primary instead")
+	InvalidImageOrder = NewError(http.StatusBadRequest, "invalid_image_order",
+		"Image order must list every image of the product exactly once")
+	InvalidSKUTemplate = NewError(http.StatusBadRequest, "invalid_sku_template",
+		"SKU template references an unknown placeholder")
+	SKUTemplateNotUnique = NewError(http.StatusBadRequest, "sku_template_not_unique",
+		"SKU template produces the same SKU for different variants")
+	EmptySKUToken = NewError(http.StatusBadRequest, "empty_sku_token",
+		"SKU template placeholder has no letters or digits to render")
+	TooManyVariants = NewError(http.StatusBadRequest, "too_many_variants",
+		"Variant matrix exceeds the maximum number of variants")
+	InsufficientStock = NewError(http.StatusConflict, "insufficient_stock",
+		"Not enough stock available to reserve")
+	StockBelowReserved = NewError(http.StatusConflict, "stock_below_reserved",
+		"Stock cannot be set below the quantity currently reserved")
+	ReservationNotFound = NewError(http.StatusNotFound, "reservation_not_found",
+		"No active reservation found for this order")
+	OrderAlreadyReserved = NewError(http.StatusConflict, "order_already_reserved",
+		"Order already holds active reservations; release them before reserving again")
+	ReservationExpired = NewError(http.StatusConflict, "reservation_expired",
+		"Reservation has expired")
+	VariantHasReservations = NewError(http.StatusConflict, "variant_has_reservations",
+		"Variant has active reservations; commit or release them first")
+	WarehouseNotFound = NewError(http.StatusNotFound, "warehouse_not_found", "Warehouse not found")
+	WarehouseNotEmpty = NewError(http.StatusConflict, "warehouse_not_empty",
+		"Warehouse still holds stock; transfer it elsewhere first")
+	DefaultWarehouseRequired = NewError(http.StatusConflict, "default_warehouse_required",
+		"Another warehouse must be made the default first")
+	InvalidTransfer = NewError(http.StatusBadRequest, "invalid_transfer",
+		"Stock can only be transferred between two different warehouses")
+	InvalidStockMovement = NewError(http.StatusBadRequest, "invalid_stock_movement",
+		"Receipts and returns must add stock")
+	VariantInStock = NewError(http.StatusConflict, "variant_in_stock",
+		"Variant is in stock; back-in-stock subscriptions are only taken for out-of-stock variants")
+	StockSubscriptionNotFound = NewError(http.StatusNotFound, "stock_subscription_not_found",
+		"Stock subscription not found")
+	CurrencyMismatch = NewError(http.StatusConflict, "currency_mismatch",
+		"Variant prices must be in the currency of their product")
 )
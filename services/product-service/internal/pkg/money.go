@@ -0,0 +1,196 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// currencyExponents maps the active ISO 4217 currency codes to the number of
+// decimals in their minor unit.
+var currencyExponents = func() map[string]int {
+	exponents := map[string]int{}
+	for exponent, codes := range map[int]string{
+		0: "BIF CLP DJF GNF ISK JPY KMF KRW PYG RWF UGX UYI VND VUV XAF XOF XPF",
+		2: "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BOV BRL BSD BTN BWP BYN " +
+			"BZD CAD CDF CHE CHF CHW CNY COP COU CRC CUP CVE CZK DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP " +
+			"GEL GHS GIP GMD GTQ GYD HKD HNL HTG HUF IDR ILS INR IRR JMD KES KGS KHR KPW KYD KZT LAK LBP " +
+			"LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR " +
+			"NZD PAB PEN PGK PHP PKR PLN QAR RON RSD RUB SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN " +
+			"SVC SYP SZL THB TJS TMT TOP TRY TTD TWD TZS UAH USD USN UYU UZS VES WST XCD XCG YER ZAR ZMW " +
+			"ZWG",
+		3: "BHD IQD JOD KWD LYD OMR TND",
+		4: "CLF UYW",
+	} {
+		for _, code := range strings.Fields(codes) {
+			exponents[code] = exponent
+		}
+	}
+	return exponents
+}()
+
+// maxMoneyDigits bounds the digits of a decimal amount so that scaling it to
+// minor units cannot overflow an int64.
+const maxMoneyDigits = 14
+
+// Money is an amount in the minor unit of an ISO 4217 currency, e.g. 1999
+// with USD for 19.99 USD. Embedded in an entity with a prefix it is stored as
+// a bigint <prefix>amount and a <prefix>currency column; in JSON it is
+// {"amount": "19.99", "currency": "USD"}, the amount a decimal string so it
+// never passes through a float.
+type Money struct {
+	Amount   int64  `gorm:"not null;default:0"`
+	Currency string `gorm:"type:char(3);not null"`
+
+	// excess counts the decimals a decoded amount had beyond what its
+	// currency allows; Amount is then unscaled and Validate rejects it.
+	excess int
+}
+
+// IsCurrency reports whether code is an active ISO 4217 currency code.
+func IsCurrency(code string) bool {
+	_, ok := currencyExponents[code]
+	return ok
+}
+
+// MinorUnitFactor is the number of minor units in one unit of currency, e.g.
+// 100 for USD and 1 for JPY.
+func MinorUnitFactor(currency string) int64 {
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		exponent = 2
+	}
+	factor := int64(1)
+	for range exponent {
+		factor *= 10
+	}
+	return factor
+}
+
+// Validate reports an unknown currency, a negative amount or an amount with
+// more decimals than the currency has.
+func (m Money) Validate() error {
+	exponent, ok := currencyExponents[m.Currency]
+	if !ok {
+		return fmt.Errorf("currency must be an ISO 4217 code, got %q", m.Currency)
+	}
+	if m.excess > 0 && exponent == 0 {
+		return fmt.Errorf("amount must be a whole number for %s", m.Currency)
+	}
+	if m.excess > 0 {
+		return fmt.Errorf("amount has more than %d decimals for %s", exponent, m.Currency)
+	}
+	if m.Amount < 0 {
+		return fmt.Errorf("amount must not be negative")
+	}
+	return nil
+}
+
+// Decimal formats the amount in major units with the decimals of its
+// currency, e.g. "19.99".
+func (m Money) Decimal() string {
+	factor := MinorUnitFactor(m.Currency)
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	whole := strconv.FormatInt(amount/factor, 10)
+	if factor == 1 {
+		return sign + whole
+	}
+	frac := strconv.FormatInt(amount%factor, 10)
+	width := len(strconv.FormatInt(factor, 10)) - 1
+	return sign + whole + "." + strings.Repeat("0", width-len(frac)) + frac
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes {"amount": "19.99", "currency": "USD"}. Malformed
+// amounts fail here; negative or over-precise ones and unknown currencies
+// decode and are left for Validate to report.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	units, scale, err := parseDecimal(raw.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid money amount %q: %w", raw.Amount, err)
+	}
+
+	*m = Money{Amount: units, Currency: raw.Currency}
+	exponent, ok := currencyExponents[raw.Currency]
+	if !ok {
+		return nil
+	}
+	if scale > exponent {
+		m.excess = scale - exponent
+		return nil
+	}
+	for range exponent - scale {
+		m.Amount *= 10
+	}
+	return nil
+}
+
+// parseDecimal reads a plain decimal such as "-19.90" into its unscaled
+// digits and the number of decimals.
+func parseDecimal(s string) (int64, int, error) {
+	digits, neg := strings.CutPrefix(s, "-")
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && frac == "") {
+		return 0, 0, fmt.Errorf("must be a decimal number such as \"19.99\"")
+	}
+	all := whole + frac
+	if strings.ContainsFunc(all, func(r rune) bool { return r < '0' || r > '9' }) {
+		return 0, 0, fmt.Errorf("must be a decimal number such as \"19.99\"")
+	}
+	if len(strings.TrimLeft(all, "0")) > maxMoneyDigits {
+		return 0, 0, fmt.Errorf("must have at most %d digits", maxMoneyDigits)
+	}
+	units, err := strconv.ParseInt(all, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if neg {
+		units = -units
+	}
+	return units, len(frac), nil
+}
+
+// MoneySQL returns an SQL expression for the amount of the money columns
+// named by prefix (e.g. "p.price" for p.price_amount and p.price_currency)
+// in major units, so prices in currencies with different minor units can be
+// filtered, sorted and bucketed by their decimal value.
+func MoneySQL(prefix string) string {
+	byExponent := map[int][]string{}
+	for code, exponent := range currencyExponents {
+		if exponent != 2 {
+			byExponent[exponent] = append(byExponent[exponent], "'"+code+"'")
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "(%s_amount::numeric / CASE", prefix)
+	for _, exponent := range slices.Sorted(maps.Keys(byExponent)) {
+		codes := byExponent[exponent]
+		slices.Sort(codes)
+		fmt.Fprintf(&sb, " WHEN %s_currency IN (%s) THEN %d", prefix, strings.Join(codes, ", "),
+			MinorUnitFactor(strings.Trim(codes[0], "'")))
+	}
+	sb.WriteString(" ELSE 100 END)")
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Money
+		wantErr bool
+		invalid bool
+	}{
+		{name: "two decimals", in: `{"amount": "19.99", "currency": "USD"}`, want: Money{Amount: 1999, Currency: "USD"}},
+		{name: "scaled up", in: `{"amount": "19.9", "currency": "USD"}`, want: Money{Amount: 1990, Currency: "USD"}},
+		{name: "whole amount", in: `{"amount": "20", "currency": "EUR"}`, want: Money{Amount: 2000, Currency: "EUR"}},
+		{name: "no minor unit", in: `{"amount": "500", "currency": "JPY"}`, want: Money{Amount: 500, Currency: "JPY"}},
+		{name: "three decimals", in: `{"amount": "1.5", "currency": "BHD"}`, want: Money{Amount: 1500, Currency: "BHD"}},
+		{name: "zero", in: `{"amount": "0", "currency": "USD"}`, want: Money{Amount: 0, Currency: "USD"}},
+		{name: "negative", in: `{"amount": "-1.00", "currency": "USD"}`, want: Money{Amount: -100, Currency: "USD"}, invalid: true},
+		{name: "too many decimals", in: `{"amount": "19.999", "currency": "USD"}`, invalid: true},
+		{name: "decimals on whole currency", in: `{"amount": "500.5", "currency": "JPY"}`, invalid: true},
+		{name: "unknown currency", in: `{"amount": "1.00", "currency": "XYZ"}`, invalid: true},
+		{name: "number instead of string", in: `{"amount": 19.99, "currency": "USD"}`, wantErr: true},
+		{name: "empty amount", in: `{"amount": "", "currency": "USD"}`, wantErr: true},
+		{name: "trailing point", in: `{"amount": "19.", "currency": "USD"}`, wantErr: true},
+		{name: "leading point", in: `{"amount": ".99", "currency": "USD"}`, wantErr: true},
+		{name: "exponent", in: `{"amount": "1e3", "currency": "USD"}`, wantErr: true},
+		{name: "too many digits", in: `{"amount": "123456789012345", "currency": "USD"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(tt.in), &m)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %+v, want error", tt.in, m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.in, err)
+			}
+			if err := m.Validate(); (err != nil) != tt.invalid {
+				t.Fatalf("Validate() = %v, want invalid %v", err, tt.invalid)
+			}
+			if !tt.invalid && (m.Amount != tt.want.Amount || m.Currency != tt.want.Currency) {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.in, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{Money{Amount: 1999, Currency: "USD"}, `{"amount":"19.99","currency":"USD"}`},
+		{Money{Amount: 5, Currency: "USD"}, `{"amount":"0.05","currency":"USD"}`},
+		{Money{Amount: -250, Currency: "EUR"}, `{"amount":"-2.50","currency":"EUR"}`},
+		{Money{Amount: 500, Currency: "JPY"}, `{"amount":"500","currency":"JPY"}`},
+		{Money{Amount: 1005, Currency: "BHD"}, `{"amount":"1.005","currency":"BHD"}`},
+		{Money{Amount: 12345, Currency: "CLF"}, `{"amount":"1.2345","currency":"CLF"}`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.m)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", tt.m, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tt.m, got, tt.want)
+		}
+
+		var back Money
+		if err := json.Unmarshal(got, &back); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", got, err)
+		}
+		if back != tt.m {
+			t.Errorf("round trip of %v = %v", tt.m, back)
+		}
+	}
+}
+
+func TestMinorUnitFactor(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int64
+	}{
+		{"USD", 100},
+		{"JPY", 1},
+		{"BHD", 1000},
+		{"CLF", 10000},
+		{"XYZ", 100},
+	}
+	for _, tt := range tests {
+		if got := MinorUnitFactor(tt.currency); got != tt.want {
+			t.Errorf("MinorUnitFactor(%q) = %d, want %d", tt.currency, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable is a PATCH field that tells an omitted key apart from an explicit
+// null: Set reports whether the key was present and Value is nil when it was
+// null.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		n.Value = nil
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.Value = &v
+	return nil
+}
+
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
@@ -0,0 +1,457 @@
+package pkg
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+type FilterOp string
+
+const (
+	OpEq       FilterOp = "eq"
+	OpNe       FilterOp = "ne"
+	OpContains FilterOp = "contains"
+	OpGt       FilterOp = "gt"
+	OpGte      FilterOp = "gte"
+	OpLt       FilterOp = "lt"
+	OpLte      FilterOp = "lte"
+	OpIn       FilterOp = "in"
+	OpIsNull   FilterOp = "is_null"
+)
+
+// FilterType is the type of the values a filter accepts. Values are checked
+// while parsing so a malformed one is rejected with a 400 instead of failing
+// in the database.
+type FilterType int
+
+const (
+	FilterString FilterType = iota
+	FilterInt
+	FilterNumber
+	FilterBool
+	FilterTime
+)
+
+// FilterField whitelists a filterable query parameter and the column and
+// operators it maps to. Requires names another filter that must be given
+// along with this one.
+type FilterField struct {
+	Column   string
+	Ops      []FilterOp
+	Type     FilterType
+	Requires string
+}
+
+// ListOptions describes what a list endpoint accepts. Sorts maps the public
+// sort field name to its column. Keyset endpoints page with signed cursors
+// holding the (sort key, id) of the last row instead of offsets, which stays
+// fast and stable on large tables; they accept a single sort field.
+// AllowDeleted lets ?include_deleted=true list soft-deleted rows as well.
+type ListOptions struct {
+	Sorts        map[string]string
+	DefaultSort  string
+	Filters      map[string]FilterField
+	Keyset       bool
+	AllowDeleted bool
+}
+
+// SortField is a sort key: Name is the public field name, which cursors
+// refer to, and Column the SQL it sorts by.
+type SortField struct {
+	Name   string
+	Column string
+	Desc   bool
+}
+
+type Filter struct {
+	Column string
+	Op     FilterOp
+	Value  string
+}
+
+// ListQuery is the parsed form of the limit, offset, cursor, sort and filter
+// query parameters of a list request.
+type ListQuery struct {
+	Limit   int
+	Offset  int
+	Sort    []SortField
+	Filters []Filter
+	Keyset  bool
+	// IncludeDeleted lists soft-deleted rows alongside live ones.
+	IncludeDeleted bool
+	// After holds the sort key values of the last row of the previous page,
+	// one per Sort entry, when paging by keyset.
+	After []string
+}
+
+// ListResponse is the standard envelope returned by list endpoints.
+type ListResponse[T any] struct {
+	Data []T `json:"data"`
+	// Total is left out on keyset pages after the first, see Count.
+	Total      *int64 `json:"total,omitempty"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Facets carries optional aggregate counts over the whole result set,
+	// not just this page.
+	Facets any `json:"facets,omitempty"`
+}
+
+// ParseListQuery reads ?limit=&offset= (or ?cursor=), ?sort=field:asc|desc
+// (comma separated for several keys) and filters written as ?field=value or
+// ?field[op]=value from the request, rejecting anything not whitelisted in
+// opts.
+func ParseListQuery(c echo.Context, opts ListOptions) (*ListQuery, error) {
+	q := &ListQuery{Limit: DefaultListLimit, Keyset: opts.Keyset}
+
+	if v := c.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return nil, InvalidQueryParameter.Withf("limit must be a positive integer")
+		}
+		q.Limit = min(limit, MaxListLimit)
+	}
+
+	sort := c.QueryParam("sort")
+	if sort == "" {
+		sort = opts.DefaultSort
+	}
+	if err := q.parseSort(sort, opts); err != nil {
+		return nil, err
+	}
+
+	if v := c.QueryParam("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil {
+			return nil, InvalidQueryParameter.Withf("malformed cursor")
+		}
+		if q.Keyset {
+			if cursor.Sort != q.sortSpec() || len(cursor.Values) != len(q.Sort) {
+				return nil, InvalidQueryParameter.Withf("cursor does not match the requested sort")
+			}
+			q.After = cursor.Values
+		} else {
+			q.Offset = cursor.O
+		}
+	} else if v := c.QueryParam("offset"); v != "" {
+		if q.Keyset {
+			return nil, InvalidQueryParameter.Withf("this endpoint pages with cursor, not offset")
+		}
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return nil, InvalidQueryParameter.Withf("offset must be a non-negative integer")
+		}
+		q.Offset = offset
+	}
+
+	if v := c.QueryParam("include_deleted"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, InvalidQueryParameter.Withf("include_deleted must be true or false")
+		}
+		if include && !opts.AllowDeleted {
+			return nil, InvalidQueryParameter.Withf("include_deleted is not supported here")
+		}
+		q.IncludeDeleted = include
+	}
+
+	used := map[string]bool{}
+	for key, values := range c.QueryParams() {
+		name, op, hasOp := parseFilterKey(key)
+		field, ok := opts.Filters[name]
+		if !ok {
+			if hasOp {
+				return nil, InvalidQueryParameter.Withf("unknown filter %q", name)
+			}
+			continue
+		}
+		if !slices.Contains(field.Ops, op) {
+			return nil, InvalidQueryParameter.Withf("filter %q does not support %q", name, op)
+		}
+		for _, value := range values {
+			if op == OpIsNull && value != "true" && value != "false" {
+				return nil, InvalidQueryParameter.Withf("%s[is_null] must be true or false", name)
+			}
+			if err := checkFilterValue(name, op, field.Type, value); err != nil {
+				return nil, err
+			}
+			q.Filters = append(q.Filters, Filter{Column: field.Column, Op: op, Value: value})
+		}
+		used[name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(used)) {
+		if required := opts.Filters[name].Requires; required != "" && !used[required] {
+			return nil, InvalidQueryParameter.Withf("filter %q must be combined with %q", name, required)
+		}
+	}
+	// Map iteration order is random; keep the generated SQL stable.
+	slices.SortFunc(q.Filters, func(a, b Filter) int {
+		return strings.Compare(a.Column+string(a.Op), b.Column+string(b.Op))
+	})
+
+	return q, nil
+}
+
+func (q *ListQuery) parseSort(sort string, opts ListOptions) error {
+	hasID := false
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, dir, _ := strings.Cut(part, ":")
+		column, ok := opts.Sorts[name]
+		if !ok {
+			return InvalidQueryParameter.Withf("cannot sort by %q", name)
+		}
+		var desc bool
+		switch strings.ToLower(dir) {
+		case "", "asc":
+		case "desc":
+			desc = true
+		default:
+			return InvalidQueryParameter.Withf("sort direction must be asc or desc")
+		}
+		q.Sort = append(q.Sort, SortField{Name: name, Column: column, Desc: desc})
+		hasID = hasID || column == "id"
+	}
+	if q.Keyset {
+		return q.normalizeKeysetSort()
+	}
+	// Always break ties on id so pages are deterministic.
+	if !hasID {
+		q.Sort = append(q.Sort, SortField{Name: "id", Column: "id"})
+	}
+	return nil
+}
+
+// normalizeKeysetSort reduces the sort to at most one key followed by id in
+// the same direction, since keyset pages compare (sort key, id) as a single
+// row value.
+func (q *ListQuery) normalizeKeysetSort() error {
+	var key *SortField
+	desc := false
+	for i := range q.Sort {
+		if q.Sort[i].Column == "id" {
+			desc = q.Sort[i].Desc
+			continue
+		}
+		if key != nil {
+			return InvalidQueryParameter.Withf("only one sort field is supported")
+		}
+		key = &q.Sort[i]
+	}
+
+	if key == nil {
+		q.Sort = []SortField{{Name: "id", Column: "id", Desc: desc}}
+		return nil
+	}
+	q.Sort = []SortField{*key, {Name: "id", Column: "id", Desc: key.Desc}}
+	return nil
+}
+
+// sortSpec identifies the sort order a keyset cursor was issued for.
+func (q *ListQuery) sortSpec() string {
+	parts := make([]string, 0, len(q.Sort))
+	for _, s := range q.Sort {
+		dir := "asc"
+		if s.Desc {
+			dir = "desc"
+		}
+		parts = append(parts, s.Name+":"+dir)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Filter applies the filters to db and returns a session that can be reused
+// for both counting and fetching.
+func (q *ListQuery) Filter(db *gorm.DB) *gorm.DB {
+	if q.IncludeDeleted {
+		db = db.Unscoped()
+	}
+	for _, f := range q.Filters {
+		col := f.Column
+		switch f.Op {
+		case OpEq:
+			db = db.Where(col+" = ?", f.Value)
+		case OpNe:
+			db = db.Where(col+" <> ?", f.Value)
+		case OpContains:
+			db = db.Where(col+" ILIKE ?", "%"+escapeLike(f.Value)+"%")
+		case OpGt:
+			db = db.Where(col+" > ?", f.Value)
+		case OpGte:
+			db = db.Where(col+" >= ?", f.Value)
+		case OpLt:
+			db = db.Where(col+" < ?", f.Value)
+		case OpLte:
+			db = db.Where(col+" <= ?", f.Value)
+		case OpIn:
+			db = db.Where(col+" IN ?", strings.Split(f.Value, ","))
+		case OpIsNull:
+			if f.Value == "true" {
+				db = db.Where(col + " IS NULL")
+			} else {
+				db = db.Where(col + " IS NOT NULL")
+			}
+		}
+	}
+	return db.Session(&gorm.Session{})
+}
+
+// Count counts the rows matched by db into total. Keyset pages after the
+// first skip it, since counting scans the whole result set and the client
+// already got the total with the first page.
+func (q *ListQuery) Count(db *gorm.DB, total *int64) error {
+	if q.Keyset && len(q.After) > 0 {
+		return nil
+	}
+	return db.Count(total).Error
+}
+
+// Page applies the sort order and limit to db, plus either the offset or,
+// for keyset queries, a WHERE (sort_key, id) > (?, ?) predicate seeking past
+// the previous page. Keyset queries fetch one row beyond the limit so
+// NewKeysetListResponse can tell whether another page follows.
+func (q *ListQuery) Page(db *gorm.DB) *gorm.DB {
+	if q.Keyset && len(q.After) == len(q.Sort) {
+		columns := make([]string, 0, len(q.Sort))
+		placeholders := make([]string, 0, len(q.Sort))
+		args := make([]interface{}, 0, len(q.Sort))
+		for i, s := range q.Sort {
+			columns = append(columns, s.Column)
+			placeholders = append(placeholders, "?")
+			args = append(args, q.After[i])
+		}
+		cmp := ">"
+		if q.Sort[0].Desc {
+			cmp = "<"
+		}
+		db = db.Where("("+strings.Join(columns, ", ")+") "+cmp+" ("+strings.Join(placeholders, ", ")+")", args...)
+	}
+
+	for _, s := range q.Sort {
+		if s.Desc {
+			db = db.Order(s.Column + " DESC")
+		} else {
+			db = db.Order(s.Column)
+		}
+	}
+	if q.Keyset {
+		return db.Limit(q.Limit + 1)
+	}
+	return db.Limit(q.Limit).Offset(q.Offset)
+}
+
+func NewListResponse[T any](data []T, total int64, q *ListQuery) *ListResponse[T] {
+	if data == nil {
+		data = make([]T, 0)
+	}
+	res := &ListResponse[T]{Data: data, Total: &total, Limit: q.Limit, Offset: q.Offset}
+	if next := q.Offset + len(data); len(data) > 0 && int64(next) < total {
+		res.NextCursor = encodeCursor(cursorPayload{O: next})
+	}
+	return res
+}
+
+// NewKeysetListResponse builds the envelope for a keyset query from rows
+// fetched with Page. key returns the value of a sort field, by its public
+// name, for an item so the next cursor can point just past the last row. The
+// extra row Page fetches is dropped; without it there is no next page. The
+// total is only reported on the first page.
+func NewKeysetListResponse[T any](data []T, total int64, q *ListQuery, key func(item T, field string) any) *ListResponse[T] {
+	if data == nil {
+		data = make([]T, 0)
+	}
+	res := &ListResponse[T]{Data: data, Limit: q.Limit}
+	if len(q.After) == 0 {
+		res.Total = &total
+	}
+	if len(data) > q.Limit {
+		res.Data = data[:q.Limit]
+		last := res.Data[q.Limit-1]
+		values := make([]string, 0, len(q.Sort))
+		for _, s := range q.Sort {
+			values = append(values, cursorValue(key(last, s.Name)))
+		}
+		res.NextCursor = encodeCursor(cursorPayload{Sort: q.sortSpec(), Values: values})
+	}
+	return res
+}
+
+// cursorValue renders a sort key as a literal Postgres can compare against
+// the column it came from.
+func cursorValue(v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// checkFilterValue makes sure every value of a filter parses as its type; an
+// in filter lists several comma separated values.
+func checkFilterValue(name string, op FilterOp, typ FilterType, value string) error {
+	if op == OpIsNull || typ == FilterString {
+		return nil
+	}
+	values := []string{value}
+	if op == OpIn {
+		values = strings.Split(value, ",")
+	}
+	for _, v := range values {
+		var err error
+		var want string
+		switch typ {
+		case FilterInt:
+			_, err = strconv.ParseInt(v, 10, 64)
+			want = "an integer"
+		case FilterNumber:
+			_, _, err = parseDecimal(v)
+			want = "a decimal number"
+		case FilterBool:
+			_, err = strconv.ParseBool(v)
+			want = "true or false"
+		case FilterTime:
+			if _, err = time.Parse(time.RFC3339, v); err != nil {
+				_, err = time.Parse(time.DateOnly, v)
+			}
+			want = "an RFC 3339 timestamp or a date"
+		}
+		if err != nil {
+			return InvalidQueryParameter.Withf("filter %q must be %s, got %q", name, want, v)
+		}
+	}
+	return nil
+}
+
+// parseFilterKey splits "name[contains]" into its field and operator. A key
+// without brackets is an equality filter.
+func parseFilterKey(key string) (string, FilterOp, bool) {
+	name, rest, ok := strings.Cut(key, "[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return key, OpEq, false
+	}
+	return name, FilterOp(strings.TrimSuffix(rest, "]")), true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
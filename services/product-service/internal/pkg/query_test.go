@@ -0,0 +1,227 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+var testListOptions = ListOptions{
+	Sorts:       map[string]string{"id": "id", "name": "name", "price": "price_amount"},
+	DefaultSort: "id:asc",
+	Filters: map[string]FilterField{
+		"name":           {Column: "name", Ops: []FilterOp{OpEq, OpContains}},
+		"stock":          {Column: "stock", Ops: []FilterOp{OpEq, OpGte, OpIn}, Type: FilterInt},
+		"price":          {Column: "price_amount", Ops: []FilterOp{OpGte, OpLte}, Type: FilterNumber, Requires: "price_currency"},
+		"price_currency": {Column: "price_currency", Ops: []FilterOp{OpEq}},
+		"active":         {Column: "active", Ops: []FilterOp{OpEq}, Type: FilterBool},
+		"created_at":     {Column: "created_at", Ops: []FilterOp{OpGte}, Type: FilterTime},
+		"deleted_at":     {Column: "deleted_at", Ops: []FilterOp{OpIsNull}, Type: FilterTime},
+	},
+	AllowDeleted: true,
+}
+
+func newQueryContext(query string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestParseListQuery(t *testing.T) {
+	SetCursorSecret([]byte("test-secret"))
+
+	tests := []struct {
+		name  string
+		query string
+		opts  ListOptions
+		want  ListQuery
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  ListQuery{Limit: DefaultListLimit, Sort: []SortField{{Name: "id", Column: "id"}}},
+		},
+		{
+			name:  "limit is capped",
+			query: "limit=500&offset=40",
+			want:  ListQuery{Limit: MaxListLimit, Offset: 40, Sort: []SortField{{Name: "id", Column: "id"}}},
+		},
+		{
+			name:  "offset cursor",
+			query: "cursor=" + encodeCursor(cursorPayload{O: 60}),
+			want:  ListQuery{Limit: DefaultListLimit, Offset: 60, Sort: []SortField{{Name: "id", Column: "id"}}},
+		},
+		{
+			name:  "sort breaks ties on id",
+			query: "sort=price:desc,name",
+			want: ListQuery{Limit: DefaultListLimit, Sort: []SortField{
+				{Name: "price", Column: "price_amount", Desc: true},
+				{Name: "name", Column: "name"},
+				{Name: "id", Column: "id"},
+			}},
+		},
+		{
+			name:  "filters are sorted by column",
+			query: "stock[gte]=5&name[contains]=shoe&active=true",
+			want: ListQuery{
+				Limit: DefaultListLimit,
+				Sort:  []SortField{{Name: "id", Column: "id"}},
+				Filters: []Filter{
+					{Column: "active", Op: OpEq, Value: "true"},
+					{Column: "name", Op: OpContains, Value: "shoe"},
+					{Column: "stock", Op: OpGte, Value: "5"},
+				},
+			},
+		},
+		{
+			name:  "typed filters",
+			query: "stock[in]=1,2,3&created_at[gte]=2024-01-31&deleted_at[is_null]=false",
+			want: ListQuery{
+				Limit: DefaultListLimit,
+				Sort:  []SortField{{Name: "id", Column: "id"}},
+				Filters: []Filter{
+					{Column: "created_at", Op: OpGte, Value: "2024-01-31"},
+					{Column: "deleted_at", Op: OpIsNull, Value: "false"},
+					{Column: "stock", Op: OpIn, Value: "1,2,3"},
+				},
+			},
+		},
+		{
+			name:  "required filter given",
+			query: "price[gte]=10.50&price_currency=USD",
+			want: ListQuery{
+				Limit: DefaultListLimit,
+				Sort:  []SortField{{Name: "id", Column: "id"}},
+				Filters: []Filter{
+					{Column: "price_amount", Op: OpGte, Value: "10.50"},
+					{Column: "price_currency", Op: OpEq, Value: "USD"},
+				},
+			},
+		},
+		{
+			name:  "unknown plain parameters are ignored",
+			query: "q=shoes",
+			want:  ListQuery{Limit: DefaultListLimit, Sort: []SortField{{Name: "id", Column: "id"}}},
+		},
+		{
+			name:  "include deleted",
+			query: "include_deleted=true",
+			want:  ListQuery{Limit: DefaultListLimit, IncludeDeleted: true, Sort: []SortField{{Name: "id", Column: "id"}}},
+		},
+		{
+			name:  "keyset sort follows the key direction",
+			query: "sort=price:desc",
+			opts:  ListOptions{Sorts: testListOptions.Sorts, Keyset: true},
+			want: ListQuery{Limit: DefaultListLimit, Keyset: true, Sort: []SortField{
+				{Name: "price", Column: "price_amount", Desc: true},
+				{Name: "id", Column: "id", Desc: true},
+			}},
+		},
+		{
+			name:  "keyset cursor",
+			query: "sort=price:desc&cursor=" + encodeCursor(cursorPayload{Sort: "price:desc,id:desc", Values: []string{"19.99", "42"}}),
+			opts:  ListOptions{Sorts: testListOptions.Sorts, Keyset: true},
+			want: ListQuery{Limit: DefaultListLimit, Keyset: true, After: []string{"19.99", "42"}, Sort: []SortField{
+				{Name: "price", Column: "price_amount", Desc: true},
+				{Name: "id", Column: "id", Desc: true},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts.Sorts == nil {
+				opts = testListOptions
+			}
+			got, err := ParseListQuery(newQueryContext(tt.query), opts)
+			if err != nil {
+				t.Fatalf("ParseListQuery(%q): %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseListQuery(%q) =\n%+v\nwant\n%+v", tt.query, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseListQueryRejects(t *testing.T) {
+	SetCursorSecret([]byte("test-secret"))
+	keyset := ListOptions{Sorts: testListOptions.Sorts, Keyset: true}
+
+	tests := []struct {
+		name  string
+		query string
+		opts  ListOptions
+	}{
+		{"zero limit", "limit=0", testListOptions},
+		{"non-numeric limit", "limit=ten", testListOptions},
+		{"negative offset", "offset=-1", testListOptions},
+		{"malformed cursor", "cursor=abc", testListOptions},
+		{"unknown sort field", "sort=color", testListOptions},
+		{"bad sort direction", "sort=name:up", testListOptions},
+		{"unknown filter", "color[eq]=red", testListOptions},
+		{"unsupported operator", "name[gt]=a", testListOptions},
+		{"non-integer filter", "stock=many", testListOptions},
+		{"non-integer in list", "stock[in]=1,x", testListOptions},
+		{"non-decimal filter", "price[gte]=1e3&price_currency=USD", testListOptions},
+		{"non-boolean filter", "active=yes", testListOptions},
+		{"malformed time filter", "created_at[gte]=yesterday", testListOptions},
+		{"malformed is_null", "deleted_at[is_null]=maybe", testListOptions},
+		{"missing required filter", "price[gte]=10", testListOptions},
+		{"bad include_deleted", "include_deleted=maybe", testListOptions},
+		{"include_deleted not allowed", "include_deleted=true", keyset},
+		{"keyset with offset", "offset=20", keyset},
+		{"keyset with two sort fields", "sort=price,name", keyset},
+		{"keyset cursor for another sort", "sort=name&cursor=" + encodeCursor(cursorPayload{Sort: "price:asc,id:asc", Values: []string{"1", "2"}}), keyset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseListQuery(newQueryContext(tt.query), tt.opts)
+			if err == nil {
+				t.Fatalf("ParseListQuery(%q) = %+v, want error", tt.query, *q)
+			}
+			if !errors.Is(err, InvalidQueryParameter) {
+				t.Errorf("ParseListQuery(%q) error = %v, want InvalidQueryParameter", tt.query, err)
+			}
+		})
+	}
+}
+
+func TestNewListResponseNextCursor(t *testing.T) {
+	SetCursorSecret([]byte("test-secret"))
+
+	tests := []struct {
+		name   string
+		offset int
+		rows   int
+		total  int64
+		next   int
+	}{
+		{"more pages", 0, 20, 45, 20},
+		{"last full page", 20, 20, 40, 0},
+		{"last partial page", 40, 5, 45, 0},
+		{"empty page", 60, 0, 45, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &ListQuery{Limit: 20, Offset: tt.offset}
+			res := NewListResponse(make([]int, tt.rows), tt.total, q)
+			if tt.next == 0 {
+				if res.NextCursor != "" {
+					t.Errorf("NextCursor = %q, want none", res.NextCursor)
+				}
+				return
+			}
+			p, err := decodeCursor(res.NextCursor)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q): %v", res.NextCursor, err)
+			}
+			if p.O != tt.next {
+				t.Errorf("next offset = %d, want %d", p.O, tt.next)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxSlugLength = 80
+
+// slugLetters covers Latin letters that do not decompose into a base letter
+// plus accents.
+var slugLetters = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "Æ", "ae", "œ", "oe", "Œ", "oe", "ø", "o", "Ø", "o",
+	"ł", "l", "Ł", "l", "đ", "d", "Đ", "d", "þ", "th", "Þ", "th", "&", " and ",
+)
+
+// Slugify turns a name into a lowercase ASCII URL segment, e.g.
+// "Crème Brûlée & Co." becomes "creme-brulee-and-co". Accents are stripped and
+// every run of other characters collapses into a single dash. The result may
+// be empty for names without any Latin letters or digits.
+func Slugify(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(slugLetters.Replace(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent left over from decomposition.
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(unicode.ToLower(r))
+		default:
+			dash = true
+		}
+	}
+
+	slug := sb.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// IsNumericID reports whether a path parameter is a numeric ID rather than a
+// slug. Generated slugs are never purely numeric.
+func IsNumericID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Crème Brûlée & Co.", "creme-brulee-and-co"},
+		{"Running Shoes", "running-shoes"},
+		{"  --Hello,   World!--  ", "hello-world"},
+		{"T-Shirt (XL)", "t-shirt-xl"},
+		{"Straße", "strasse"},
+		{"Smørrebrød", "smorrebrod"},
+		{"Łódź", "lodz"},
+		{"ﬁne", "fine"},
+		{"iPhone 15 Pro", "iphone-15-pro"},
+		{"日本語", ""},
+		{"", ""},
+		{strings.Repeat("a", 100), strings.Repeat("a", 80)},
+		{strings.Repeat("abcdefghi ", 10), strings.TrimSuffix(strings.Repeat("abcdefghi-", 8), "-")},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.name); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsNumericID(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"42", true},
+		{"0", true},
+		{"", false},
+		{"-1", false},
+		{"4a", false},
+		{"running-shoes", false},
+		{"١٢", false},
+	}
+	for _, tt := range tests {
+		if got := IsNumericID(tt.s); got != tt.want {
+			t.Errorf("IsNumericID(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package pkg
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 
 	"github.com/go-playground/validator/v10"
@@ -18,6 +19,22 @@ type ValidationErrors struct {
 	Message string `json:"message"`
 }
 
+// NewCustomValidator returns the request validator with the rules of this
+// package registered: "money" checks a Money with Money.Validate. Nullable
+// fields are validated as their value, so omitempty skips both an omitted key
+// and an explicit null.
+func NewCustomValidator() *CustomValidator {
+	v := validator.New()
+	_ = v.RegisterValidation("money", func(fl validator.FieldLevel) bool {
+		m, ok := fl.Field().Interface().(Money)
+		return ok && m.Validate() == nil
+	})
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(Nullable[int]).Value
+	}, Nullable[int]{})
+	return &CustomValidator{Validator: v}
+}
+
 func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.Validator.Struct(i)
 }
@@ -27,12 +44,11 @@ func BindAndValidate[T any](fn func(echo.Context, *T) error) echo.HandlerFunc {
 		rawRequest := new(T)
 
 		if err := c.Bind(rawRequest); err != nil {
-			return c.JSON(400, echo.Map{"error": "Invalid request"})
+			return InvalidRequestBody.Wrap(err)
 		}
 
 		if err := c.Validate(rawRequest); err != nil {
-			validationErrors := FormatValidationError(err)
-			return c.JSON(400, echo.Map{"errors": validationErrors})
+			return ValidationFailed.WithDetails(FormatValidationError(err))
 		}
 
 		return fn(c, rawRequest)
@@ -65,6 +81,10 @@ func GetErrorMessage(fe validator.FieldError) string {
 		return fmt.Sprintf("Value must be at least %s characters long", fe.Param())
 	case "max":
 		return fmt.Sprintf("Value must be at most %s characters long", fe.Param())
+	case "money":
+		if m, ok := fe.Value().(Money); ok && m.Validate() != nil {
+			return m.Validate().Error()
+		}
 	}
 	return fe.Error()
 }
@@ -80,3 +100,22 @@ func StringToUint(s string) uint {
 	}
 	return uint(u64)
 }
+
+// ParseID parses a positive ID that fits the bigint id columns.
+func ParseID(s string) (uint, bool) {
+	u64, err := strconv.ParseUint(s, 10, 63)
+	if err != nil || u64 == 0 {
+		return 0, false
+	}
+	return uint(u64), true
+}
+
+// ParamID reads a numeric ID path parameter. A value that is not an ID cannot
+// name a record, so it fails with notFound before any query runs.
+func ParamID(c echo.Context, name string, notFound *Error) (string, error) {
+	id := c.Param(name)
+	if _, ok := ParseID(id); !ok {
+		return "", notFound
+	}
+	return id, nil
+}
@@ -0,0 +1,224 @@
+package rendition
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/repository"
+	"product-service/internal/storage"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// sweepInterval is how often the pool looks for pending images that were not
+// queued, e.g. because the queue was full or the service restarted.
+const sweepInterval = time.Minute
+
+// Pool generates image renditions in the background. Images are queued by ID
+// and processed by a fixed number of workers; the database status column is
+// the source of truth, so nothing is lost if the queue overflows.
+type Pool struct {
+	imageRepo *repository.ProductImageRepository
+	storage   storage.Storage
+	specs     []Spec
+	workers   int
+	queue     chan uint
+
+	mu     sync.Mutex
+	queued map[uint]bool
+	rerun  map[uint]bool // queued again while a worker was processing it
+}
+
+func NewPool(imageRepo *repository.ProductImageRepository, storage storage.Storage, specs []Spec, workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{
+		imageRepo: imageRepo,
+		storage:   storage,
+		specs:     specs,
+		workers:   workers,
+		queue:     make(chan uint, workers*64),
+		queued:    make(map[uint]bool),
+		rerun:     make(map[uint]bool),
+	}
+}
+
+// Start launches the workers and the pending-image sweeper. They stop when
+// ctx is cancelled.
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.work(ctx)
+	}
+	go p.sweep(ctx)
+}
+
+// Enqueue schedules renditions for an image without blocking. If the queue is
+// full the image stays pending and is picked up by the next sweep. An image
+// that is already being processed is queued again once its worker is done,
+// since the run in progress may have read the image before it changed.
+func (p *Pool) Enqueue(imageID uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queued[imageID] {
+		p.rerun[imageID] = true
+		return
+	}
+	p.enqueueLocked(imageID)
+}
+
+func (p *Pool) enqueueLocked(imageID uint) {
+	select {
+	case p.queue <- imageID:
+		p.queued[imageID] = true
+	default:
+		log.Warnf("Rendition queue full, image %d deferred to next sweep", imageID)
+	}
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-p.queue:
+			// Requests made before the image is loaded are covered by this run.
+			p.mu.Lock()
+			delete(p.rerun, id)
+			p.mu.Unlock()
+
+			if err := p.process(ctx, id); err != nil {
+				log.Errorf("Failed to generate renditions for image %d: %v", id, err)
+			}
+
+			p.mu.Lock()
+			delete(p.queued, id)
+			if p.rerun[id] {
+				delete(p.rerun, id)
+				p.enqueueLocked(id)
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *Pool) sweep(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		ids, err := p.imageRepo.GetPendingImageIDs(cap(p.queue))
+		if err != nil {
+			log.Errorf("Failed to load pending images: %v", err)
+		}
+		for _, id := range ids {
+			p.Enqueue(id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pool) process(ctx context.Context, imageID uint) error {
+	image, err := p.imageRepo.GetImageByID(imageID)
+	if errors.Is(err, pkg.ProductImageNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := p.safeGenerate(ctx, image); err != nil {
+		if err := p.imageRepo.SetRenditionStatus(image.ID, image.RenditionVersion, entity.RenditionStatusFailed); err != nil {
+			log.Errorf("Failed to mark image %d as failed: %v", image.ID, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// safeGenerate is generate with a panic, as decoders and encoders may raise
+// on malformed input, turned into an error so the worker keeps running and
+// the image is marked failed.
+func (p *Pool) safeGenerate(ctx context.Context, image *entity.ProductImage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while rendering: %v", r)
+		}
+	}()
+	return p.generate(ctx, image)
+}
+
+// generate renders and stores every spec for the version of image that was
+// loaded. Blobs are keyed by that version so a run that turns out to be stale
+// never overwrites the output of a newer one.
+func (p *Pool) generate(ctx context.Context, image *entity.ProductImage) error {
+	rc, err := p.storage.Get(ctx, image.StorageKey)
+	if err != nil {
+		return err
+	}
+	src, format, err := Decode(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	renditions := make([]entity.ProductImageRendition, 0, len(p.specs))
+	written := make(map[string]bool, len(p.specs))
+	for _, spec := range p.specs {
+		out, err := Render(src, format, spec)
+		if err != nil {
+			return fmt.Errorf("rendition %s: %w", spec.Name, err)
+		}
+		key := fmt.Sprintf("products/%d/renditions/%d/v%d/%s%s",
+			image.ProductID, image.ID, image.RenditionVersion, spec.Name, out.Extension)
+		if err := p.storage.Put(ctx, key, bytes.NewReader(out.Data), out.ContentType); err != nil {
+			return err
+		}
+		written[key] = true
+		renditions = append(renditions, entity.ProductImageRendition{
+			Name:        spec.Name,
+			URL:         p.storage.URL(key),
+			StorageKey:  key,
+			ContentType: out.ContentType,
+			Width:       out.Width,
+			Height:      out.Height,
+		})
+	}
+
+	previous, err := p.imageRepo.SaveRenditions(image.ID, image.RenditionVersion, renditions)
+	if errors.Is(err, pkg.ProductImageNotFound) || errors.Is(err, repository.ErrStaleRenditions) {
+		// The image was deleted or queued again while we were working; drop
+		// what we wrote and leave it to the newer run, if any.
+		p.deleteBlobs(ctx, written)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	stale := make(map[string]bool)
+	for _, rd := range previous {
+		if !written[rd.StorageKey] {
+			stale[rd.StorageKey] = true
+		}
+	}
+	p.deleteBlobs(ctx, stale)
+	return nil
+}
+
+func (p *Pool) deleteBlobs(ctx context.Context, keys map[string]bool) {
+	for key := range keys {
+		if err := p.storage.Delete(ctx, key); err != nil {
+			log.Errorf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
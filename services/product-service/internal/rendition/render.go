@@ -0,0 +1,102 @@
+package rendition
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Output is an encoded derivative ready to be stored.
+type Output struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// maxPixels bounds the width x height of a source image, since decoding one
+// allocates its full bitmap regardless of how small the file is.
+const maxPixels = 50_000_000
+
+// Decode reads any of the upload formats accepted by the image endpoints. The
+// dimensions are checked from the header first so a small file declaring a
+// huge image is rejected before its bitmap is allocated.
+func Decode(r io.Reader) (image.Image, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, "", fmt.Errorf("image is %dx%d, more than %d pixels", cfg.Width, cfg.Height, maxPixels)
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// Render scales src to fit the spec and encodes it. sourceFormat is the name
+// reported by Decode and is used when the spec keeps the source format.
+func Render(src image.Image, sourceFormat string, spec Spec) (*Output, error) {
+	dst := resize(src, spec.MaxWidth, spec.MaxHeight)
+
+	format := spec.Format
+	if format == FormatSource {
+		format = FormatPNG
+		if sourceFormat == "jpeg" {
+			format = FormatJPEG
+		}
+	}
+
+	var buf bytes.Buffer
+	out := &Output{Width: dst.Bounds().Dx(), Height: dst.Bounds().Dy()}
+	switch format {
+	case FormatJPEG:
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Extension = "image/jpeg", ".jpg"
+	case FormatWebP:
+		if err := nativewebp.Encode(&buf, dst, nil); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Extension = "image/webp", ".webp"
+	default:
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Extension = "image/png", ".png"
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// resize returns src scaled to fit inside maxW x maxH keeping its aspect
+// ratio. Images that already fit are copied unchanged.
+func resize(src image.Image, maxW, maxH int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxW || h > maxH {
+		if w*maxH > h*maxW {
+			h = max(1, h*maxW/w)
+			w = maxW
+		} else {
+			w = max(1, w*maxH/h)
+			h = maxH
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
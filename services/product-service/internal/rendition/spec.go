@@ -0,0 +1,79 @@
+package rendition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatSource = "" // keep the source format (PNG and GIF sources become PNG)
+	FormatJPEG   = "jpeg"
+	FormatPNG    = "png"
+	FormatWebP   = "webp"
+)
+
+// Spec describes one derivative: the image is scaled down to fit inside
+// MaxWidth x MaxHeight, never upscaled, and encoded as Format.
+type Spec struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+	Format    string
+}
+
+var DefaultSpecs = []Spec{
+	{Name: "thumbnail", MaxWidth: 200, MaxHeight: 200},
+	{Name: "medium", MaxWidth: 600, MaxHeight: 600},
+	{Name: "large", MaxWidth: 1200, MaxHeight: 1200},
+	{Name: "webp", MaxWidth: 1200, MaxHeight: 1200, Format: FormatWebP},
+}
+
+// ParseSpecs reads a comma separated list of name:WIDTHxHEIGHT[:format]
+// entries, e.g. "thumbnail:200x200,webp:1200x1200:webp".
+func ParseSpecs(s string) ([]Spec, error) {
+	var specs []Spec
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("rendition %q: expected name:WIDTHxHEIGHT[:format]", entry)
+		}
+		spec := Spec{Name: parts[0]}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("rendition %q: duplicate name", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		w, h, ok := strings.Cut(parts[1], "x")
+		if !ok {
+			return nil, fmt.Errorf("rendition %q: size must be WIDTHxHEIGHT", entry)
+		}
+		var err error
+		if spec.MaxWidth, err = strconv.Atoi(w); err != nil || spec.MaxWidth <= 0 {
+			return nil, fmt.Errorf("rendition %q: invalid width", entry)
+		}
+		if spec.MaxHeight, err = strconv.Atoi(h); err != nil || spec.MaxHeight <= 0 {
+			return nil, fmt.Errorf("rendition %q: invalid height", entry)
+		}
+
+		if len(parts) == 3 {
+			switch parts[2] {
+			case FormatJPEG, FormatPNG, FormatWebP:
+				spec.Format = parts[2]
+			default:
+				return nil, fmt.Errorf("rendition %q: unsupported format %q", entry, parts[2])
+			}
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no renditions configured")
+	}
+	return specs, nil
+}
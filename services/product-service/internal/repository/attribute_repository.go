@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AttributeRepository struct {
+	db *gorm.DB
+}
+
+func NewAttributeRepository(db *gorm.DB) *AttributeRepository {
+	return &AttributeRepository{db: db}
+}
+
+func (r *AttributeRepository) GetAllAttributes(q *pkg.ListQuery) ([]response.AttributeResponse, int64, error) {
+	query := q.Filter(r.db.Model(&entity.Attribute{}))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var attributes []entity.Attribute
+	if err := q.Page(query.Preload("Values", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})).Find(&attributes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]response.AttributeResponse, 0, len(attributes))
+	for i := range attributes {
+		res = append(res, *toAttributeResponse(&attributes[i]))
+	}
+	return res, total, nil
+}
+
+func (r *AttributeRepository) GetAttributeByID(id string) (*response.AttributeResponse, error) {
+	attribute, err := r.findAttribute(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("attribute_id = ?", attribute.ID).Order("id").Find(&attribute.Values).Error; err != nil {
+		return nil, err
+	}
+	return toAttributeResponse(attribute), nil
+}
+
+func (r *AttributeRepository) AddAttribute(attribute *request.AttributeRequest) (*response.AttributeResponse, error) {
+	attr := entity.Attribute{
+		Name: attribute.Name,
+		Type: attribute.Type,
+		Unit: attribute.Unit,
+	}
+	if attr.Type == "" {
+		attr.Type = entity.AttributeTypeText
+	}
+	if err := r.db.Create(&attr).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Attribute name already exists")
+		}
+		return nil, err
+	}
+	return toAttributeResponse(&attr), nil
+}
+
+func (r *AttributeRepository) UpdateAttribute(id string, attribute *request.AttributePatchRequest) (
+	*response.AttributeResponse, error) {
+	updates := map[string]interface{}{}
+	if attribute.Name != nil {
+		updates["name"] = *attribute.Name
+	}
+	if attribute.Type != nil {
+		updates["type"] = *attribute.Type
+	}
+	if attribute.Unit != nil {
+		updates["unit"] = *attribute.Unit
+	}
+	if len(updates) == 0 {
+		return nil, pkg.NoFieldsToUpdate
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var attr entity.Attribute
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&attr, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.AttributeNotFound
+			}
+			return err
+		}
+
+		// The values already stored must fit the new type.
+		if attribute.Type != nil && *attribute.Type != attr.Type {
+			var values []entity.AttributeValue
+			if err := tx.Where("attribute_id = ?", attr.ID).Order("id").Find(&values).Error; err != nil {
+				return err
+			}
+			retyped := attr
+			retyped.Type = *attribute.Type
+			for _, v := range values {
+				if !isValidAttributeValue(&retyped, v.Value) {
+					return pkg.AttributeTypeConflict.Withf("Attribute value %q is not a valid %s value",
+						v.Value, retyped.Type)
+				}
+			}
+		}
+		return tx.Model(&attr).Updates(updates).Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Attribute name already exists")
+		}
+		return nil, err
+	}
+	return r.GetAttributeByID(id)
+}
+
+func (r *AttributeRepository) DeleteAttribute(id string) error {
+	attr, err := r.findAttribute(id)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Soft-deleted variants count as well: restoring one must not bring
+		// back a link to a deleted value.
+		var usage int64
+		if err := tx.Table("variant_attribute_values vav").
+			Joins("JOIN attribute_values av ON av.id = vav.attribute_value_id").
+			Where("av.attribute_id = ?", attr.ID).
+			Count(&usage).Error; err != nil {
+			return err
+		}
+		if usage > 0 {
+			return pkg.AttributeInUse
+		}
+
+		if err := tx.Where("attribute_id = ?", attr.ID).Delete(&entity.AttributeValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(attr).Error
+	})
+}
+
+func (r *AttributeRepository) GetValuesByAttributeID(attributeID string, q *pkg.ListQuery) (
+	[]response.AttributeValueResponse, int64, error) {
+	attr, err := r.findAttribute(attributeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := q.Filter(r.db.Model(&entity.AttributeValue{}).Where("attribute_id = ?", attr.ID))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var values []entity.AttributeValue
+	if err := q.Page(query).Find(&values).Error; err != nil {
+		return nil, 0, err
+	}
+	return toAttributeValueResponses(values), total, nil
+}
+
+func (r *AttributeRepository) AddAttributeValue(attributeID string, value *request.AttributeValueRequest) (
+	*response.AttributeValueResponse, error) {
+	attr, err := r.findAttribute(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidAttributeValue(attr, value.Value) {
+		return nil, pkg.InvalidAttributeValue
+	}
+
+	v := entity.AttributeValue{
+		AttributeID: attr.ID,
+		Value:       value.Value,
+		Swatch:      value.Swatch,
+	}
+	if err := r.db.Create(&v).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Attribute value already exists")
+		}
+		return nil, err
+	}
+	return toAttributeValueResponse(&v), nil
+}
+
+func (r *AttributeRepository) UpdateAttributeValue(attributeID, valueID string,
+	value *request.AttributeValuePatchRequest) (*response.AttributeValueResponse, error) {
+	updates := map[string]interface{}{}
+	if value.Value != nil {
+		updates["value"] = *value.Value
+	}
+	if value.Swatch != nil {
+		updates["swatch"] = *value.Swatch
+	}
+	if len(updates) == 0 {
+		return nil, pkg.NoFieldsToUpdate
+	}
+
+	attr, err := r.findAttribute(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if value.Value != nil && !isValidAttributeValue(attr, *value.Value) {
+		return nil, pkg.InvalidAttributeValue
+	}
+	v, err := r.findAttributeValue(attr.ID, valueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(v).Updates(updates).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Attribute value already exists")
+		}
+		return nil, err
+	}
+	v, err = r.findAttributeValue(attr.ID, valueID)
+	if err != nil {
+		return nil, err
+	}
+	return toAttributeValueResponse(v), nil
+}
+
+func (r *AttributeRepository) DeleteAttributeValue(attributeID, valueID string) error {
+	attr, err := r.findAttribute(attributeID)
+	if err != nil {
+		return err
+	}
+	v, err := r.findAttributeValue(attr.ID, valueID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var usage int64
+		if err := tx.Table("variant_attribute_values vav").
+			Where("vav.attribute_value_id = ?", v.ID).
+			Count(&usage).Error; err != nil {
+			return err
+		}
+		if usage > 0 {
+			return pkg.AttributeValueInUse
+		}
+		return tx.Delete(v).Error
+	})
+}
+
+func (r *AttributeRepository) findAttribute(id string) (*entity.Attribute, error) {
+	var attribute entity.Attribute
+	if err := r.db.First(&attribute, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.AttributeNotFound
+		}
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+func (r *AttributeRepository) findAttributeValue(attributeID uint, valueID string) (*entity.AttributeValue, error) {
+	var value entity.AttributeValue
+	if err := r.db.First(&value, "id = ? AND attribute_id = ?", valueID, attributeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.AttributeValueNotFound
+		}
+		return nil, err
+	}
+	return &value, nil
+}
+
+// isValidAttributeValue checks a value against the attribute type; number
+// attributes only accept finite numeric values, the unit lives on the
+// attribute.
+func isValidAttributeValue(attribute *entity.Attribute, value string) bool {
+	if attribute.Type != entity.AttributeTypeNumber {
+		return true
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	return err == nil && !math.IsNaN(n) && !math.IsInf(n, 0)
+}
+
+func toAttributeResponse(a *entity.Attribute) *response.AttributeResponse {
+	res := &response.AttributeResponse{
+		ID:   a.ID,
+		Name: a.Name,
+		Type: a.Type,
+		Unit: a.Unit,
+	}
+	if len(a.Values) > 0 {
+		res.Values = toAttributeValueResponses(a.Values)
+	}
+	return res
+}
+
+func toAttributeValueResponse(v *entity.AttributeValue) *response.AttributeValueResponse {
+	return &response.AttributeValueResponse{
+		ID:          v.ID,
+		AttributeID: v.AttributeID,
+		Value:       v.Value,
+		Swatch:      v.Swatch,
+	}
+}
+
+func toAttributeValueResponses(values []entity.AttributeValue) []response.AttributeValueResponse {
+	res := make([]response.AttributeValueResponse, 0, len(values))
+	for i := range values {
+		res = append(res, *toAttributeValueResponse(&values[i]))
+	}
+	return res
+}
@@ -0,0 +1,437 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// MoveCategory re-parents a category, carrying its whole subtree along, and
+// places it at the requested position among its new siblings. The siblings it
+// leaves behind are renumbered to close the gap.
+func (r *CategoryRepository) MoveCategory(id string, move *request.CategoryMoveRequest) (
+	*response.CategoryResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		category, err := findCategory(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if move.ParentID != nil {
+			if *move.ParentID == category.ID {
+				return pkg.CategoryCannotBeItsOwnParent
+			}
+			if !categoryExists(tx, pkg.UintToString(*move.ParentID)) {
+				return pkg.ParentCategoryNotFound
+			}
+			cycle, err := isAncestorOf(tx, category.ID, *move.ParentID)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return pkg.CategoryCycle
+			}
+		}
+
+		siblings, err := loadSiblings(tx, move.ParentID)
+		if err != nil {
+			return err
+		}
+		siblings = slices.DeleteFunc(siblings, func(c entity.Category) bool { return c.ID == category.ID })
+		index := len(siblings)
+		if move.Position != nil && *move.Position < index {
+			index = *move.Position
+		}
+
+		if err := tx.Model(&entity.Category{}).
+			Where("id = ?", category.ID).
+			Update("parent_id", move.ParentID).Error; err != nil {
+			return err
+		}
+		if err := moveCategoryPath(tx, category, move.ParentID); err != nil {
+			return err
+		}
+		// Force the position write: the moved row's old position means
+		// nothing among its new siblings.
+		oldParentID := category.ParentID
+		category.Position = -1
+		if err := saveCategoryPositions(tx, slices.Insert(siblings, index, *category)); err != nil {
+			return err
+		}
+		if sameParent(oldParentID, move.ParentID) {
+			return nil
+		}
+		return compactCategoryPositions(tx, oldParentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCategoryByID(id)
+}
+
+// ReorderCategories sets the sibling order of the children of parentID, or of
+// the top-level categories when parentID is nil. The order must contain every
+// sibling exactly once.
+func (r *CategoryRepository) ReorderCategories(parentID *uint, order *request.CategoryOrderRequest) (
+	[]response.CategoryResponse, error) {
+	var ordered []entity.Category
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		if parentID != nil && !categoryExists(tx, pkg.UintToString(*parentID)) {
+			return pkg.CategoryNotFound
+		}
+
+		siblings, err := loadSiblings(tx, parentID)
+		if err != nil {
+			return err
+		}
+		if len(order.CategoryIDs) != len(siblings) {
+			return pkg.InvalidCategoryOrder
+		}
+
+		byID := make(map[uint]entity.Category, len(siblings))
+		for _, c := range siblings {
+			byID[c.ID] = c
+		}
+		ordered = make([]entity.Category, 0, len(siblings))
+		for _, id := range order.CategoryIDs {
+			c, ok := byID[id]
+			if !ok {
+				return pkg.InvalidCategoryOrder
+			}
+			delete(byID, id)
+			ordered = append(ordered, c)
+		}
+		return saveCategoryPositions(tx, ordered)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toCategoryResponses(ordered), nil
+}
+
+// MergeCategory folds the source category into the target: its children are
+// appended to the target's children, its soft-deleted children and its
+// products (including soft-deleted ones) are re-pointed at the target and the
+// source is deleted, leaving its slugs as redirects to the target. The
+// source's former siblings are renumbered to close the gap.
+func (r *CategoryRepository) MergeCategory(sourceID string, targetID uint) (*response.CategoryResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		source, err := findCategory(tx, sourceID)
+		if err != nil {
+			return err
+		}
+		if !categoryExists(tx, pkg.UintToString(targetID)) {
+			return pkg.TargetCategoryNotFound
+		}
+		invalid, err := isAncestorOf(tx, source.ID, targetID)
+		if err != nil {
+			return err
+		}
+		if invalid {
+			return pkg.InvalidMergeTarget
+		}
+
+		children, err := loadSiblings(tx, &source.ID)
+		if err != nil {
+			return err
+		}
+		targetChildren, err := loadSiblings(tx, &targetID)
+		if err != nil {
+			return err
+		}
+		// Deleted children follow too, so they can still be restored or
+		// purged once the source is gone.
+		var deletedChildren []entity.Category
+		if err := tx.Unscoped().
+			Where("parent_id = ? AND deleted_at IS NOT NULL", source.ID).
+			Find(&deletedChildren).Error; err != nil {
+			return err
+		}
+		if len(children) > 0 || len(deletedChildren) > 0 {
+			if err := tx.Unscoped().Model(&entity.Category{}).
+				Where("parent_id = ?", source.ID).
+				Update("parent_id", targetID).Error; err != nil {
+				return err
+			}
+			for _, moved := range [][]entity.Category{children, deletedChildren} {
+				for i := range moved {
+					if err := moveCategoryPath(tx, &moved[i], &targetID); err != nil {
+						return err
+					}
+				}
+			}
+			if err := saveCategoryPositions(tx, append(targetChildren, children...)); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(&entity.Product{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+		// Links to the merged category keep working through its current and
+		// earlier slugs.
+		if err := moveSlugHistory(tx, entity.SlugEntityCategory, source.ID, targetID); err != nil {
+			return err
+		}
+		if err := addSlugRedirect(tx, entity.SlugEntityCategory, targetID, source.Slug); err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.Category{}, source.ID).Error; err != nil {
+			return err
+		}
+		return compactCategoryPositions(tx, source.ParentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCategoryByID(pkg.UintToString(targetID))
+}
+
+// deleteCategorySubtree deletes a category and every descendant. It refuses
+// while any of them still has products.
+func deleteCategorySubtree(tx *gorm.DB, category *entity.Category) error {
+	var ids []uint
+	if err := subtreeIDs(tx, category).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	var productCount int64
+	if err := tx.Model(&entity.Product{}).Where("category_id IN ?", ids).Count(&productCount).Error; err != nil {
+		return err
+	}
+	if productCount > 0 {
+		return pkg.CategoryHasProducts
+	}
+	return tx.Where("id IN ?", ids).Delete(&entity.Category{}).Error
+}
+
+func findCategory(tx *gorm.DB, id string) (*entity.Category, error) {
+	var category entity.Category
+	if err := tx.First(&category, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.CategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// loadSiblings returns the children of parentID, or the top-level categories
+// when it is nil, in display order.
+func loadSiblings(tx *gorm.DB, parentID *uint) ([]entity.Category, error) {
+	query := tx.Model(&entity.Category{})
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var siblings []entity.Category
+	if err := query.Order("position, name, id").Find(&siblings).Error; err != nil {
+		return nil, err
+	}
+	return siblings, nil
+}
+
+// saveCategoryPositions renumbers categories 0..n-1 in slice order, touching
+// only the rows whose position changed.
+func saveCategoryPositions(tx *gorm.DB, categories []entity.Category) error {
+	for i := range categories {
+		if categories[i].Position == i {
+			continue
+		}
+		if err := tx.Model(&entity.Category{}).
+			Where("id = ?", categories[i].ID).
+			Update("position", i).Error; err != nil {
+			return err
+		}
+		categories[i].Position = i
+	}
+	return nil
+}
+
+// compactCategoryPositions renumbers the live children of parentID, or the
+// top-level categories when it is nil, after one of them left.
+func compactCategoryPositions(tx *gorm.DB, parentID *uint) error {
+	siblings, err := loadSiblings(tx, parentID)
+	if err != nil {
+		return err
+	}
+	return saveCategoryPositions(tx, siblings)
+}
+
+func toCategoryResponse(c *entity.Category) *response.CategoryResponse {
+	return &response.CategoryResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Slug:        c.Slug,
+		Description: c.Description,
+		ParentID:    c.ParentID,
+		Position:    c.Position,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		DeletedAt:   deletedAt(c.DeletedAt),
+	}
+}
+
+func toCategoryResponses(categories []entity.Category) []response.CategoryResponse {
+	res := make([]response.CategoryResponse, 0, len(categories))
+	for i := range categories {
+		res = append(res, *toCategoryResponse(&categories[i]))
+	}
+	return res
+}
+
+// RestoreCategory undeletes a category and, with cascade, every deleted
+// category below it. The parent must be live. Restored categories are
+// appended to their siblings and get a fresh slug if a live category took
+// theirs in the meantime.
+func (r *CategoryRepository) RestoreCategory(id string, cascade bool) (*response.CategoryResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		category, err := findCategory(tx.Unscoped(), id)
+		if err != nil {
+			return err
+		}
+		if category.ParentID != nil && !categoryExists(tx, pkg.UintToString(*category.ParentID)) {
+			return pkg.CategoryParentDeleted
+		}
+
+		var restoring []entity.Category
+		if category.DeletedAt.Valid {
+			restoring = append(restoring, *category)
+		}
+		if cascade && category.Path == "" {
+			// Never backfilled; a prefix match would hit every row.
+			if _, err := RebuildCategoryPaths(tx); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&entity.Category{}).
+				Where("id = ?", category.ID).
+				Pluck("path", &category.Path).Error; err != nil {
+				return err
+			}
+		}
+		// A category still without a path sits in a parent_id cycle, and
+		// only it is restored.
+		if cascade && category.Path != "" {
+			// Parents sort before their children, so each row's parent is
+			// live by the time it is restored.
+			var below []entity.Category
+			if err := tx.Unscoped().
+				Where("path LIKE ? AND id <> ? AND deleted_at IS NOT NULL", category.Path+"%", category.ID).
+				Order("depth, position, id").
+				Find(&below).Error; err != nil {
+				return err
+			}
+			restoring = append(restoring, below...)
+		}
+
+		for i := range restoring {
+			if err := restoreCategory(tx, &restoring[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Category name already exists")
+		}
+		return nil, err
+	}
+	return r.GetCategoryByID(id)
+}
+
+// PurgeCategory permanently removes a soft-deleted category together with
+// its soft-deleted descendants, so a cascade-deleted subtree goes in one
+// call. It refuses while any live category sits below it or any product,
+// deleted or not, points into the subtree.
+func (r *CategoryRepository) PurgeCategory(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		category, err := findCategory(tx.Unscoped(), id)
+		if err != nil {
+			return err
+		}
+		if !category.DeletedAt.Valid {
+			return pkg.RecordNotDeleted
+		}
+
+		ids := []uint{category.ID}
+		if category.Path != "" {
+			if err := tx.Unscoped().Model(&entity.Category{}).
+				Where("path LIKE ?", category.Path+"%").
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+		}
+
+		// Live rows in the subtree, and children a missing path left out of
+		// it, would be orphaned.
+		var childCount int64
+		if err := tx.Unscoped().Model(&entity.Category{}).
+			Where("(id IN ? AND deleted_at IS NULL) OR (parent_id IN ? AND id NOT IN ?)", ids, ids, ids).
+			Count(&childCount).Error; err != nil {
+			return err
+		}
+		if childCount > 0 {
+			return pkg.CategoryHasChildren.Withf("Category still has live child categories below it")
+		}
+		var productCount int64
+		if err := tx.Unscoped().Model(&entity.Product{}).
+			Where("category_id IN ?", ids).
+			Count(&productCount).Error; err != nil {
+			return err
+		}
+		if productCount > 0 {
+			return pkg.CategoryHasProducts.Withf("Category still has products, including deleted ones")
+		}
+
+		for _, id := range ids {
+			if err := deleteSlugHistory(tx, entity.SlugEntityCategory, id); err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&entity.Category{}).Error
+	})
+}
+
+func restoreCategory(tx *gorm.DB, category *entity.Category) error {
+	siblings, err := loadSiblings(tx, category.ParentID)
+	if err != nil {
+		return err
+	}
+	slug, err := restoredSlug(tx, "categories", entity.SlugEntityCategory, category.Name, category.Slug, category.ID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Model(&entity.Category{}).
+		Where("id = ?", category.ID).
+		Updates(map[string]interface{}{"deleted_at": nil, "position": len(siblings), "slug": slug}).Error; err != nil {
+		return err
+	}
+	return dropSlugRedirect(tx, entity.SlugEntityCategory, slug)
+}
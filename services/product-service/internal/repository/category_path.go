@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"product-service/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// Categories carry a materialized path of their ancestor IDs such as
+// "/1/4/9/" plus their depth below the top level, so a whole subtree is a
+// single indexed "path LIKE '/1/4/%'" predicate. Paths include soft-deleted
+// categories so restoring them needs no repair.
+
+// categoryPath returns the path and depth of category id placed under
+// parentID.
+func categoryPath(tx *gorm.DB, parentID *uint, id uint) (string, int, error) {
+	if parentID == nil {
+		return fmt.Sprintf("/%d/", id), 0, nil
+	}
+	var parent entity.Category
+	if err := tx.Unscoped().Select("id, path, depth").First(&parent, *parentID).Error; err != nil {
+		return "", 0, err
+	}
+	return fmt.Sprintf("%s%d/", parent.Path, id), parent.Depth + 1, nil
+}
+
+// setCategoryPath stores the path of a newly created category.
+func setCategoryPath(tx *gorm.DB, category *entity.Category) error {
+	path, depth, err := categoryPath(tx, category.ParentID, category.ID)
+	if err != nil {
+		return err
+	}
+	category.Path, category.Depth = path, depth
+	return tx.Model(&entity.Category{}).
+		Where("id = ?", category.ID).
+		Updates(map[string]interface{}{"path": path, "depth": depth}).Error
+}
+
+// moveCategoryPath rewrites the paths and depths of a category and its whole
+// subtree after it has been re-parented under parentID.
+func moveCategoryPath(tx *gorm.DB, category *entity.Category, parentID *uint) error {
+	if category.Path == "" {
+		// Never backfilled; a prefix match would hit every row.
+		_, err := RebuildCategoryPaths(tx)
+		return err
+	}
+	path, depth, err := categoryPath(tx, parentID, category.ID)
+	if err != nil {
+		return err
+	}
+	if path == category.Path {
+		return nil
+	}
+	return tx.Exec(`
+		UPDATE categories
+		SET path = ? || substr(path, ?), depth = depth + ?
+		WHERE path LIKE ?`,
+		path, len(category.Path)+1, depth-category.Depth, category.Path+"%").Error
+}
+
+// subtreeIDs is a subquery selecting the live category and all of its live
+// descendants.
+func subtreeIDs(tx *gorm.DB, category *entity.Category) *gorm.DB {
+	query := tx.Model(&entity.Category{}).Select("id")
+	if category.Path == "" {
+		return query.Where("id = ?", category.ID)
+	}
+	return query.Where("path LIKE ?", category.Path+"%")
+}
+
+// RebuildCategoryPaths recomputes every category path and depth from
+// parent_id and returns how many rows changed. Categories caught in a
+// parent_id cycle are unreachable from the top level and left untouched.
+func RebuildCategoryPaths(db *gorm.DB) (int64, error) {
+	res := db.Exec(`
+		WITH RECURSIVE tree AS (
+			SELECT id, '/' || id || '/' AS path, 0 AS depth
+			FROM categories
+			WHERE parent_id IS NULL
+			UNION ALL
+			SELECT c.id, t.path || c.id || '/', t.depth + 1
+			FROM categories c
+			JOIN tree t ON c.parent_id = t.id
+		)
+		UPDATE categories
+		SET path = tree.path, depth = tree.depth
+		FROM tree
+		WHERE categories.id = tree.id
+			AND (categories.path IS DISTINCT FROM tree.path OR categories.depth <> tree.depth)`)
+	return res.RowsAffected, res.Error
+}
+
+// HasMissingCategoryPaths reports whether any category still lacks a path,
+// e.g. because it predates the column.
+func HasMissingCategoryPaths(db *gorm.DB) (bool, error) {
+	var missing bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM categories WHERE path IS NULL OR path = '')").Scan(&missing).Error
+	return missing, err
+}
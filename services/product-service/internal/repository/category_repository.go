@@ -9,6 +9,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryRepository struct {
@@ -19,17 +20,8 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
-func (r *CategoryRepository) GetAllCategories() ([]response.CategoryResponse, error) {
-	var categories []response.CategoryResponse
-	if err := r.db.Model(&entity.Category{}).
-		Select("id, name, description, parent_id").
-		Scan(&categories).Error; err != nil {
-		return nil, err
-	}
-	if categories == nil {
-		categories = make([]response.CategoryResponse, 0)
-	}
-	return categories, nil
+func (r *CategoryRepository) GetAllCategories(q *pkg.ListQuery) ([]response.CategoryResponse, int64, error) {
+	return r.listCategories(r.db.Model(&entity.Category{}), q)
 }
 
 func (r *CategoryRepository) CheckIfCategoryExists(id string) bool {
@@ -45,131 +37,413 @@ func (r *CategoryRepository) CheckIfCategoryExists(id string) bool {
 	return true
 }
 
-func (r *CategoryRepository) GetCategoryByID(id string) (*response.CategoryResponse, error) {
+// categoryTreeLockKey identifies the transaction-level advisory lock held
+// while the shape of the category tree changes.
+const categoryTreeLockKey = 7_401_001
+
+func lockCategoryTree(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", categoryTreeLockKey).Error
+}
+
+func categoryExists(tx *gorm.DB, id string) bool {
 	var category entity.Category
-	if err := r.db.First(&category, "id = ?", id).Error; err != nil {
+	err := tx.Select("id").First(&category, "id = ?", id).Error
+	return err == nil
+}
+
+// isAncestorOf reports whether ancestorID is categoryID itself or lies on
+// the parent chain above it. Soft-deleted categories are followed too, so a
+// later restore cannot close a loop.
+func isAncestorOf(tx *gorm.DB, ancestorID, categoryID uint) (bool, error) {
+	var found bool
+	err := tx.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM categories WHERE id = ?
+			UNION
+			SELECT c.id, c.parent_id FROM categories c
+			JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = ?)`, categoryID, ancestorID).Scan(&found).Error
+	return found, err
+}
+
+func (r *CategoryRepository) GetCategoryByID(id string) (*response.CategoryResponse, error) {
+	category, err := findCategory(r.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCategoryResponse(category), nil
+}
+
+// GetCategoryPath returns the ancestor chain of a category ordered from the
+// top-level category down to the category itself.
+func (r *CategoryRepository) GetCategoryPath(id string) ([]response.CategoryBreadcrumbResponse, error) {
+	var path []response.CategoryBreadcrumbResponse
+	if err := r.db.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, name, slug, parent_id, 0 AS distance, ARRAY[id] AS path
+			FROM categories
+			WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id, c.name, c.slug, c.parent_id, a.distance + 1, a.path || c.id
+			FROM categories c
+			JOIN ancestors a ON c.id = a.parent_id
+			WHERE c.deleted_at IS NULL AND NOT c.id = ANY(a.path)
+		)
+		SELECT id, name, slug FROM ancestors ORDER BY distance DESC`, id).
+		Scan(&path).Error; err != nil {
 		return nil, err
 	}
+	if len(path) == 0 {
+		return nil, pkg.CategoryNotFound
+	}
+	return path, nil
+}
 
-	return &response.CategoryResponse{
-		ID:          category.ID,
-		Name:        category.Name,
-		Description: category.Description,
-		ParentID:    category.ParentID,
-	}, nil
+// ResolveCategorySlug returns the ID and current slug of the category
+// addressed by slug, which may be one it used before a rename.
+func (r *CategoryRepository) ResolveCategorySlug(slug string) (uint, string, error) {
+	id, current, err := resolveSlug(r.db, "categories", entity.SlugEntityCategory, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", pkg.CategoryNotFound
+	}
+	return id, current, err
 }
 
-func (r *CategoryRepository) GetChildCategoriesByID(id string) ([]response.CategoryResponse, error) {
+// renameCategorySlug derives a new slug from the new name and keeps the old
+// one as a redirect.
+func renameCategorySlug(tx *gorm.DB, category *entity.Category, name string) error {
+	slug, err := uniqueSlug(tx, "categories", entity.SlugEntityCategory, name, category.ID)
+	if err != nil {
+		return err
+	}
+	if slug == category.Slug {
+		return nil
+	}
+	if err := tx.Model(&entity.Category{}).Where("id = ?", category.ID).Update("slug", slug).Error; err != nil {
+		return err
+	}
+	if err := dropSlugRedirect(tx, entity.SlugEntityCategory, slug); err != nil {
+		return err
+	}
+	return addSlugRedirect(tx, entity.SlugEntityCategory, category.ID, category.Slug)
+}
+
+func (r *CategoryRepository) GetChildCategoriesByID(id string, q *pkg.ListQuery) (
+	[]response.CategoryResponse, int64, error) {
+	category, err := findCategory(r.db, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.listCategories(r.db.Model(&entity.Category{}).Where("parent_id = ?", category.ID), q)
+}
+
+func (r *CategoryRepository) listCategories(db *gorm.DB, q *pkg.ListQuery) ([]response.CategoryResponse, int64, error) {
+	query := q.Filter(db)
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
 	var categories []response.CategoryResponse
-	if err := r.db.Model(&entity.Category{}).
-		Select("id, name, description, parent_id").
-		Where("parent_id = ?", id).
+	if err := q.Page(query).
+		Select("id, name, slug, description, parent_id, position, created_at, updated_at, deleted_at").
 		Scan(&categories).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if categories == nil {
 		categories = make([]response.CategoryResponse, 0)
 	}
-	return categories, nil
+	return categories, total, nil
 }
 
-func (r *CategoryRepository) AddCategory(category *request.CategoryRequest) error {
-	if category.ParentID != nil {
-		if !r.CheckIfCategoryExists(pkg.UintToString(*category.ParentID)) {
-			return pkg.ParentCategoryNotFound
+func (r *CategoryRepository) AddCategory(category *request.CategoryRequest) (*response.CategoryResponse, error) {
+	var created entity.Category
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
 		}
-	}
-	cat := entity.Category{
-		Name:        category.Name,
-		Description: category.Description,
-		ParentID:    category.ParentID,
-	}
-	err := r.db.Create(&cat).Error
+		if category.ParentID != nil {
+			if !categoryExists(tx, pkg.UintToString(*category.ParentID)) {
+				return pkg.ParentCategoryNotFound
+			}
+		}
+		siblings, err := loadSiblings(tx, category.ParentID)
+		if err != nil {
+			return err
+		}
+		slug, err := uniqueSlug(tx, "categories", entity.SlugEntityCategory, category.Name, 0)
+		if err != nil {
+			return err
+		}
+		created = entity.Category{
+			Name:        category.Name,
+			Slug:        slug,
+			Description: category.Description,
+			ParentID:    category.ParentID,
+			Position:    len(siblings),
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return err
+		}
+		if err := setCategoryPath(tx, &created); err != nil {
+			return err
+		}
+		return dropSlugRedirect(tx, entity.SlugEntityCategory, slug)
+	})
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return pkg.DuplicateEntry
+			return nil, pkg.DuplicateEntry.Withf("Category name already exists")
 		}
-		return err
+		return nil, err
 	}
-	return nil
+	return toCategoryResponse(&created), nil
 }
 
+// UpdateCategory applies the fields present in the patch and returns the row
+// as stored. A parent_id of null moves the category to the top level.
 func (r *CategoryRepository) UpdateCategory(id string, category *request.CategoryPatchRequest) (
 	*response.CategoryResponse, error) {
-	if !r.CheckIfCategoryExists(id) {
-		return nil, pkg.CategoryNotFound
+	updates := map[string]interface{}{}
+	if category.Name != nil {
+		updates["name"] = *category.Name
+	}
+	if category.Description != nil {
+		updates["description"] = *category.Description
+	}
+	if len(updates) == 0 && !category.ParentID.Set {
+		return nil, pkg.NoFieldsToUpdate
 	}
+	parentID := category.ParentID.Value
 
-	if category.ParentID != nil {
-		if *category.ParentID == pkg.StringToUint(id) {
-			return nil, pkg.CategoryCannotBeItsOwnParent
+	var updated entity.Category
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if category.ParentID.Set {
+			// Serialize re-parenting so two concurrent moves cannot each pass
+			// the cycle check and together form a loop.
+			if err := lockCategoryTree(tx); err != nil {
+				return err
+			}
 		}
-		if !r.CheckIfCategoryExists(pkg.UintToString(*category.ParentID)) {
-			return nil, pkg.ParentCategoryNotFound
+		existing, err := findCategory(tx, id)
+		if err != nil {
+			return err
+		}
+
+		parentChanged := false
+		if category.ParentID.Set {
+			if parentID != nil {
+				if *parentID == existing.ID {
+					return pkg.CategoryCannotBeItsOwnParent
+				}
+				if !categoryExists(tx, pkg.UintToString(*parentID)) {
+					return pkg.ParentCategoryNotFound
+				}
+				cycle, err := isAncestorOf(tx, existing.ID, *parentID)
+				if err != nil {
+					return err
+				}
+				if cycle {
+					return pkg.CategoryCycle
+				}
+			}
+			parentChanged = !sameParent(existing.ParentID, parentID)
+		}
+		if parentChanged {
+			// A category that changes parent goes to the end of its new
+			// siblings.
+			siblings, err := loadSiblings(tx, parentID)
+			if err != nil {
+				return err
+			}
+			updates["parent_id"] = parentID
+			updates["position"] = len(siblings)
+		}
+
+		if category.Name != nil && *category.Name != existing.Name {
+			if err := renameCategorySlug(tx, existing, *category.Name); err != nil {
+				return err
+			}
 		}
-	}
 
-	if err := r.db.Model(&entity.Category{}).Where("id = ?", id).Updates(category).Error; err != nil {
+		if err := tx.Model(&updated).
+			Clauses(clause.Returning{}).
+			Where("id = ?", existing.ID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if parentChanged {
+			return moveCategoryPath(tx, existing, parentID)
+		}
+		return nil
+	})
+	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return nil, pkg.DuplicateEntry
+			return nil, pkg.DuplicateEntry.Withf("Category name already exists")
 		}
 		return nil, err
 	}
+	return toCategoryResponse(&updated), nil
+}
+
+func sameParent(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// DeleteCategory deletes a category without children. With cascade its whole
+// subtree is deleted instead, provided none of it has products. The remaining
+// siblings are renumbered to close the gap.
+func (r *CategoryRepository) DeleteCategory(id string, cascade bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		category, err := findCategory(tx, id)
+		if err != nil {
+			return err
+		}
+		if cascade {
+			if err := deleteCategorySubtree(tx, category); err != nil {
+				return err
+			}
+			return compactCategoryPositions(tx, category.ParentID)
+		}
+
+		// Check if category is parent of any child category
+		var childCount int64
+		if err := tx.Model(&entity.Category{}).
+			Where("parent_id = ?", category.ID).
+			Count(&childCount).Error; err != nil {
+			return err
+		}
+		if childCount > 0 {
+			return pkg.CategoryHasChildren
+		}
+		if err := tx.Delete(&entity.Category{}, category.ID).Error; err != nil {
+			return err
+		}
+		return compactCategoryPositions(tx, category.ParentID)
+	})
+}
 
-	return nil, nil
+type categoryTreeRow struct {
+	response.CategoryResponse
+	Depth int
 }
 
-func (r *CategoryRepository) DeleteCategory(id string) error {
-	if !r.CheckIfCategoryExists(id) {
-		return pkg.CategoryNotFound
+// GetCategoryTree loads the category hierarchy with a single recursive query
+// and assembles it in memory. With a rootID the result holds only that
+// category and its descendants. maxDepth limits how many levels below the
+// top are returned; a negative value means no limit. Siblings are ordered by
+// position, then name.
+func (r *CategoryRepository) GetCategoryTree(rootID *uint, maxDepth int) ([]*response.CategoryTreeResponse, error) {
+	if rootID != nil && !r.CheckIfCategoryExists(pkg.UintToString(*rootID)) {
+		return nil, pkg.CategoryNotFound
 	}
-	// Check if category is parent of any child category
-	var childCount int64
-	if err := r.db.Model(&entity.Category{}).Where("parent_id = ?", id).Count(&childCount).Error; err != nil {
-		return err
+
+	anchor := "parent_id IS NULL"
+	args := []interface{}{}
+	if rootID != nil {
+		anchor = "id = ?"
+		args = append(args, *rootID)
+	}
+	depthLimit := ""
+	if maxDepth >= 0 {
+		depthLimit = "AND t.depth < ?"
+		args = append(args, maxDepth)
 	}
 
-	if childCount > 0 {
-		return pkg.CategoryHasChildren // You should define this error in your pkg
+	// The path guard stops the recursion on a parent_id cycle instead of
+	// looping forever.
+	var rows []categoryTreeRow
+	if err := r.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id, name, slug, description, parent_id, position, created_at, updated_at,
+				0 AS depth, ARRAY[id] AS path
+			FROM categories
+			WHERE `+anchor+` AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id, c.name, c.slug, c.description, c.parent_id, c.position, c.created_at, c.updated_at,
+				t.depth + 1, t.path || c.id
+			FROM categories c
+			JOIN tree t ON c.parent_id = t.id
+			WHERE c.deleted_at IS NULL AND NOT c.id = ANY(t.path) `+depthLimit+`
+		)
+		SELECT id, name, slug, description, parent_id, position, created_at, updated_at, depth
+		FROM tree
+		ORDER BY depth, position, name, id`, args...).
+		Scan(&rows).Error; err != nil {
+		return nil, err
 	}
 
-	return r.db.Delete(&entity.Category{}, "id = ?", id).Error
+	return assembleCategoryTree(rows), nil
 }
 
-func (r *CategoryRepository) GetCategoryTree() ([]*response.CategoryTreeResponse, error) {
-	return r.buildCategoryTree(nil)
+// assembleCategoryTree links rows ordered by depth into a forest. A parent
+// always precedes its children, so sibling order is the row order.
+func assembleCategoryTree(rows []categoryTreeRow) []*response.CategoryTreeResponse {
+	roots := make([]*response.CategoryTreeResponse, 0)
+	nodes := make(map[uint]*response.CategoryTreeResponse, len(rows))
+	for _, row := range rows {
+		node := &response.CategoryTreeResponse{CategoryResponse: row.CategoryResponse}
+		nodes[row.ID] = node
+		if row.Depth == 0 {
+			roots = append(roots, node)
+			continue
+		}
+		parent := nodes[*row.ParentID]
+		parent.Children = append(parent.Children, node)
+	}
+	return roots
 }
 
-func (r *CategoryRepository) buildCategoryTree(parentID *uint) ([]*response.CategoryTreeResponse, error) {
-	var flatCats []response.CategoryResponse
-	if parentID == nil {
-		err := r.db.Model(&entity.Category{}).
-			Select("id, name, description, parent_id").
-			Where("parent_id IS NULL").
-			Scan(&flatCats).Error
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		err := r.db.Model(&entity.Category{}).
-			Select("id, name, description, parent_id").
-			Where("parent_id = ?", parentID).
-			Scan(&flatCats).Error
-		if err != nil {
-			return nil, err
-		}
+func (r *CategoryRepository) GetProductsByCategoryID(id string, includeDescendants bool, q *pkg.ListQuery) (
+	[]response.ProductResponse, int64, error) {
+	query, err := r.categoryProducts(id, includeDescendants)
+	if err != nil {
+		return nil, 0, err
 	}
+	query = q.Filter(query)
 
-	var treeCats []*response.CategoryTreeResponse
-	for i := range flatCats {
-		children, _ := r.buildCategoryTree(&flatCats[i].ID)
-		treeCat := &response.CategoryTreeResponse{
-			CategoryResponse: flatCats[i],
-			Children:         children,
-		}
-		treeCats = append(treeCats, treeCat)
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var products []entity.Product
+	if err := q.Page(preloadPrimaryImage(query)).Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	return toProductResponses(products), total, nil
+}
+
+// GetCategoryProductFacets counts the products listed by
+// GetProductsByCategoryID by category, attribute value and price bucket.
+func (r *CategoryRepository) GetCategoryProductFacets(id string, includeDescendants bool, q *pkg.ListQuery,
+	buckets []float64) (*response.ProductFacets, error) {
+	query, err := r.categoryProducts(id, includeDescendants)
+	if err != nil {
+		return nil, err
+	}
+	return productFacets(r.db, q.Filter(query).Select("id"), buckets)
+}
+
+// categoryProducts selects the products of a category and, with
+// includeDescendants, of every category below it.
+func (r *CategoryRepository) categoryProducts(id string, includeDescendants bool) (*gorm.DB, error) {
+	category, err := findCategory(r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if includeDescendants {
+		return r.db.Model(&entity.Product{}).Where("category_id IN (?)", subtreeIDs(r.db, category)), nil
 	}
-	return treeCats, nil
+	return r.db.Model(&entity.Product{}).Where("category_id = ?", category.ID), nil
 }
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"fmt"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/response"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type categoryFacetRow struct {
+	ID    uint
+	Name  string
+	Count int64
+}
+
+type attributeFacetRow struct {
+	AttributeID   uint
+	AttributeName string
+	AttributeType string
+	ValueID       uint
+	Value         string
+	Swatch        string
+	Count         int64
+}
+
+type priceFacetRow struct {
+	Currency string
+	Bucket   int
+	Count    int64
+}
+
+// GetProductFacets counts the products matching the list filters in q by
+// category, attribute value and price bucket. buckets are the ascending
+// boundaries between price ranges, applied to each currency separately since
+// amounts are only comparable within one.
+func (r *ProductRepository) GetProductFacets(q *pkg.ListQuery, buckets []float64) (*response.ProductFacets, error) {
+	matching := q.Filter(r.db.Model(&entity.Product{})).Select("id")
+	return productFacets(r.db, matching, buckets)
+}
+
+// GetSearchFacets is GetProductFacets for the products matching a search.
+func (r *ProductRepository) GetSearchFacets(term string, q *pkg.ListQuery, buckets []float64) (
+	*response.ProductFacets, error) {
+	tsq := toPrefixTSQuery(term)
+	if tsq == "" {
+		return nil, pkg.EmptySearchQuery
+	}
+	return productFacets(r.db, r.searchQuery(tsq, q).Select("p.id"), buckets)
+}
+
+// productFacets computes every facet over the products whose IDs are returned
+// by the matching subquery.
+func productFacets(db, matching *gorm.DB, buckets []float64) (*response.ProductFacets, error) {
+	facets := &response.ProductFacets{
+		Categories: []response.CategoryFacet{},
+		Attributes: []response.AttributeFacet{},
+		Prices:     []response.PriceFacet{},
+	}
+
+	var categories []categoryFacetRow
+	if err := db.Table("products p").
+		Select("c.id, c.name, count(*) AS count").
+		Joins("JOIN categories c ON c.id = p.category_id").
+		Where("p.id IN (?)", matching).
+		Group("c.id, c.name").
+		Order("count DESC, c.name").
+		Scan(&categories).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		facets.Categories = append(facets.Categories, response.CategoryFacet(c))
+	}
+
+	// A product counts once per value even if several of its variants
+	// share it.
+	var values []attributeFacetRow
+	if err := db.Table("variants v").
+		Select(`a.id AS attribute_id, a.name AS attribute_name, a.type AS attribute_type,
+			av.id AS value_id, av.value, av.swatch, count(DISTINCT v.product_id) AS count`).
+		Joins("JOIN variant_attribute_values vav ON vav.variant_id = v.id").
+		Joins("JOIN attribute_values av ON av.id = vav.attribute_value_id AND av.deleted_at IS NULL").
+		Joins("JOIN attributes a ON a.id = av.attribute_id AND a.deleted_at IS NULL").
+		Where("v.deleted_at IS NULL AND v.product_id IN (?)", matching).
+		Group("a.id, a.name, a.type, av.id, av.value, av.swatch").
+		Order("a.name, av.value").
+		Scan(&values).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		n := len(facets.Attributes)
+		if n == 0 || facets.Attributes[n-1].ID != v.AttributeID {
+			facets.Attributes = append(facets.Attributes, response.AttributeFacet{
+				ID:     v.AttributeID,
+				Name:   v.AttributeName,
+				Type:   v.AttributeType,
+				Values: []response.AttributeValueFacet{},
+			})
+			n++
+		}
+		facets.Attributes[n-1].Values = append(facets.Attributes[n-1].Values, response.AttributeValueFacet{
+			ID:     v.ValueID,
+			Value:  v.Value,
+			Swatch: v.Swatch,
+			Count:  v.Count,
+		})
+	}
+
+	var prices []priceFacetRow
+	bucket, args := priceBucketExpr(pkg.MoneySQL("p.price"), buckets)
+	if err := db.Table("products p").
+		Select("p.price_currency AS currency, "+bucket+" AS bucket, count(*) AS count", args...).
+		Where("p.id IN (?)", matching).
+		Group("currency, bucket").
+		Order("currency").
+		Scan(&prices).Error; err != nil {
+		return nil, err
+	}
+	var currencies []string
+	counts := make(map[string]map[int]int64)
+	for _, p := range prices {
+		if counts[p.Currency] == nil {
+			currencies = append(currencies, p.Currency)
+			counts[p.Currency] = make(map[int]int64, len(buckets)+1)
+		}
+		counts[p.Currency][p.Bucket] = p.Count
+	}
+	for _, currency := range currencies {
+		for i := 0; i <= len(buckets); i++ {
+			facet := response.PriceFacet{Currency: currency, Count: counts[currency][i]}
+			if i > 0 {
+				facet.Min = &buckets[i-1]
+			}
+			if i < len(buckets) {
+				facet.Max = &buckets[i]
+			}
+			facets.Prices = append(facets.Prices, facet)
+		}
+	}
+	return facets, nil
+}
+
+// priceBucketExpr builds a CASE expression numbering the price range column
+// falls into: 0 below the first boundary up to len(buckets) at or above the
+// last one.
+func priceBucketExpr(column string, buckets []float64) (string, []interface{}) {
+	if len(buckets) == 0 {
+		return "0", nil
+	}
+	var sb strings.Builder
+	args := make([]interface{}, 0, len(buckets))
+	sb.WriteString("CASE")
+	for i, b := range buckets {
+		fmt.Fprintf(&sb, " WHEN %s < ? THEN %d", column, i)
+		args = append(args, b)
+	}
+	fmt.Fprintf(&sb, " ELSE %d END", len(buckets))
+	return sb.String(), args
+}
@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrStaleRenditions is returned by SaveRenditions when the image was queued
+// for regeneration after the renditions being saved were started.
+var ErrStaleRenditions = errors.New("renditions are from an outdated version")
+
+type ProductImageRepository struct {
+	db *gorm.DB
+}
+
+func NewProductImageRepository(db *gorm.DB) *ProductImageRepository {
+	return &ProductImageRepository{db: db}
+}
+
+func (r *ProductImageRepository) CheckIfProductExists(productID string) bool {
+	var product entity.Product
+	err := r.db.Select("id").First(&product, "id = ?", productID).Error
+	return err == nil
+}
+
+func (r *ProductImageRepository) GetImagesByProductID(productID string, q *pkg.ListQuery) (
+	[]response.ProductImageResponse, int64, error) {
+	if !r.CheckIfProductExists(productID) {
+		return nil, 0, pkg.ProductNotFound
+	}
+
+	query := q.Filter(r.db.Model(&entity.ProductImage{}).Where("product_id = ?", productID))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var images []entity.ProductImage
+	if err := q.Page(query.Preload("Renditions")).Find(&images).Error; err != nil {
+		return nil, 0, err
+	}
+	return toProductImageResponses(images), total, nil
+}
+
+// AddImage stores the image row for an already uploaded blob. The first image
+// of a product always becomes primary; a new primary demotes the previous one.
+// Without a position the image is appended after the existing ones.
+func (r *ProductImageRepository) AddImage(productID string, image *entity.ProductImage, position *int) (
+	*response.ProductImageResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		images, err := lockProductImages(tx, productID)
+		if err != nil {
+			return err
+		}
+
+		image.ProductID = pkg.StringToUint(productID)
+		if len(images) == 0 {
+			image.IsPrimary = true
+		}
+		if image.IsPrimary {
+			if err := tx.Model(&entity.ProductImage{}).
+				Where("product_id = ? AND is_primary", productID).
+				Update("is_primary", false).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+
+		index := len(images)
+		if position != nil && *position < index {
+			index = *position
+		}
+		return savePositions(tx, slices.Insert(images, index, *image))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.getImage(productID, pkg.UintToString(image.ID))
+}
+
+func (r *ProductImageRepository) UpdateImage(productID, imageID string, patch *request.ProductImagePatchRequest) (
+	*response.ProductImageResponse, error) {
+	if patch.IsPrimary == nil && patch.Position == nil {
+		return nil, pkg.NoFieldsToUpdate
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		images, err := lockProductImages(tx, productID)
+		if err != nil {
+			return err
+		}
+		index := slices.IndexFunc(images, func(img entity.ProductImage) bool {
+			return pkg.UintToString(img.ID) == imageID
+		})
+		if index < 0 {
+			return pkg.ProductImageNotFound
+		}
+		image := images[index]
+
+		if patch.IsPrimary != nil && *patch.IsPrimary != image.IsPrimary {
+			if !*patch.IsPrimary {
+				return pkg.PrimaryImageRequired
+			}
+			if err := tx.Model(&entity.ProductImage{}).
+				Where("product_id = ? AND is_primary", productID).
+				Update("is_primary", false).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&image).Update("is_primary", true).Error; err != nil {
+				return err
+			}
+		}
+
+		if patch.Position != nil {
+			images = slices.Delete(images, index, index+1)
+			newIndex := min(*patch.Position, len(images))
+			images = slices.Insert(images, newIndex, image)
+			return savePositions(tx, images)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.getImage(productID, imageID)
+}
+
+// ReorderImages sets positions from the given order, which must contain every
+// image of the product exactly once.
+func (r *ProductImageRepository) ReorderImages(productID string, order *request.ProductImageOrderRequest) (
+	[]response.ProductImageResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		images, err := lockProductImages(tx, productID)
+		if err != nil {
+			return err
+		}
+		if len(order.ImageIDs) != len(images) {
+			return pkg.InvalidImageOrder
+		}
+
+		byID := make(map[uint]entity.ProductImage, len(images))
+		for _, img := range images {
+			byID[img.ID] = img
+		}
+		ordered := make([]entity.ProductImage, 0, len(images))
+		for _, id := range order.ImageIDs {
+			img, ok := byID[id]
+			if !ok {
+				return pkg.InvalidImageOrder
+			}
+			delete(byID, id)
+			ordered = append(ordered, img)
+		}
+		return savePositions(tx, ordered)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var images []entity.ProductImage
+	if err := r.db.Preload("Renditions").
+		Where("product_id = ?", productID).
+		Order("position, id").
+		Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return toProductImageResponses(images), nil
+}
+
+// DeleteImage removes the image row and its renditions permanently and
+// returns them so the caller can delete the blobs. When the primary image is
+// removed the next image in order is promoted.
+func (r *ProductImageRepository) DeleteImage(productID, imageID string) (*entity.ProductImage, error) {
+	var deleted entity.ProductImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		images, err := lockProductImages(tx, productID)
+		if err != nil {
+			return err
+		}
+		index := slices.IndexFunc(images, func(img entity.ProductImage) bool {
+			return pkg.UintToString(img.ID) == imageID
+		})
+		if index < 0 {
+			return pkg.ProductImageNotFound
+		}
+		deleted = images[index]
+
+		if err := tx.Where("product_image_id = ?", deleted.ID).Find(&deleted.Renditions).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("product_image_id = ?", deleted.ID).
+			Delete(&entity.ProductImageRendition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&entity.ProductImage{}, deleted.ID).Error; err != nil {
+			return err
+		}
+		images = slices.Delete(images, index, index+1)
+
+		if deleted.IsPrimary && len(images) > 0 {
+			if err := tx.Model(&images[0]).Update("is_primary", true).Error; err != nil {
+				return err
+			}
+		}
+		return savePositions(tx, images)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+func (r *ProductImageRepository) getImage(productID, imageID string) (*response.ProductImageResponse, error) {
+	var image entity.ProductImage
+	if err := r.db.Preload("Renditions").
+		First(&image, "id = ? AND product_id = ?", imageID, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ProductImageNotFound
+		}
+		return nil, err
+	}
+	return toProductImageResponse(&image), nil
+}
+
+// GetImageByID loads an image for rendition processing.
+func (r *ProductImageRepository) GetImageByID(id uint) (*entity.ProductImage, error) {
+	var image entity.ProductImage
+	if err := r.db.First(&image, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ProductImageNotFound
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+// GetPendingImageIDs returns images whose renditions have not been generated
+// yet, oldest first.
+func (r *ProductImageRepository) GetPendingImageIDs(limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&entity.ProductImage{}).
+		Where("rendition_status = ?", entity.RenditionStatusPending).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SetRenditionStatus sets the status of an image unless it has been queued for
+// regeneration since version was read.
+func (r *ProductImageRepository) SetRenditionStatus(imageID uint, version int, status string) error {
+	return r.db.Model(&entity.ProductImage{}).
+		Where("id = ? AND rendition_version = ?", imageID, version).
+		Update("rendition_status", status).Error
+}
+
+// MarkRenditionsPending flags an image of the product for regeneration and
+// bumps its rendition version, so a run that is still working from the
+// previous version cannot save its output.
+func (r *ProductImageRepository) MarkRenditionsPending(productID, imageID string) (*entity.ProductImage, error) {
+	var image entity.ProductImage
+	result := r.db.Model(&image).Clauses(clause.Returning{}).
+		Where("id = ? AND product_id = ?", imageID, productID).
+		Updates(map[string]interface{}{
+			"rendition_status":  entity.RenditionStatusPending,
+			"rendition_version": gorm.Expr("rendition_version + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, pkg.ProductImageNotFound
+	}
+	return &image, nil
+}
+
+// SaveRenditions replaces the renditions of an image generated from version
+// and marks it ready. The previous rows are returned so the caller can delete
+// blobs that were not overwritten. ProductImageNotFound is returned if the
+// image was deleted while its renditions were being generated, and
+// ErrStaleRenditions if it was queued for regeneration again meanwhile.
+func (r *ProductImageRepository) SaveRenditions(imageID uint, version int, renditions []entity.ProductImageRendition) (
+	[]entity.ProductImageRendition, error) {
+	var previous []entity.ProductImageRendition
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var image entity.ProductImage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "rendition_version").
+			First(&image, imageID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ProductImageNotFound
+			}
+			return err
+		}
+		if image.RenditionVersion != version {
+			return ErrStaleRenditions
+		}
+
+		if err := tx.Where("product_image_id = ?", imageID).Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("product_image_id = ?", imageID).
+			Delete(&entity.ProductImageRendition{}).Error; err != nil {
+			return err
+		}
+		for i := range renditions {
+			renditions[i].ProductImageID = imageID
+		}
+		if len(renditions) > 0 {
+			if err := tx.Create(&renditions).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&image).Update("rendition_status", entity.RenditionStatusReady).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// lockProductImages locks the product row so concurrent image operations on
+// the same product are serialized, then returns its images in display order.
+func lockProductImages(tx *gorm.DB, productID string) ([]entity.ProductImage, error) {
+	var product entity.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&product, "id = ?", productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ProductNotFound
+		}
+		return nil, err
+	}
+
+	var images []entity.ProductImage
+	if err := tx.Where("product_id = ?", product.ID).Order("position, id").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// savePositions renumbers images 0..n-1 in slice order, touching only the
+// rows whose position changed.
+func savePositions(tx *gorm.DB, images []entity.ProductImage) error {
+	for i := range images {
+		if images[i].Position == i {
+			continue
+		}
+		if err := tx.Model(&entity.ProductImage{}).
+			Where("id = ?", images[i].ID).
+			Update("position", i).Error; err != nil {
+			return err
+		}
+		images[i].Position = i
+	}
+	return nil
+}
+
+func toProductImageResponse(img *entity.ProductImage) *response.ProductImageResponse {
+	res := &response.ProductImageResponse{
+		ID:              img.ID,
+		ProductID:       img.ProductID,
+		URL:             img.URL,
+		ContentType:     img.ContentType,
+		Size:            img.Size,
+		IsPrimary:       img.IsPrimary,
+		Position:        img.Position,
+		RenditionStatus: img.RenditionStatus,
+	}
+	if len(img.Renditions) > 0 {
+		res.Renditions = make(map[string]string, len(img.Renditions))
+		for _, rd := range img.Renditions {
+			res.Renditions[rd.Name] = rd.URL
+		}
+	}
+	return res
+}
+
+func toProductImageResponses(images []entity.ProductImage) []response.ProductImageResponse {
+	res := make([]response.ProductImageResponse, 0, len(images))
+	for i := range images {
+		res = append(res, *toProductImageResponse(&images[i]))
+	}
+	return res
+}
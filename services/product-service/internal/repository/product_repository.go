@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+func (r *ProductRepository) GetAllProducts(q *pkg.ListQuery) ([]response.ProductResponse, int64, error) {
+	query := q.Filter(r.db.Model(&entity.Product{}))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var products []entity.Product
+	if err := q.Page(preloadPrimaryImage(query)).Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	return toProductResponses(products), total, nil
+}
+
+func (r *ProductRepository) CheckIfProductExists(id string) bool {
+	var product entity.Product
+	err := r.db.Select("id").First(&product, "id = ?", id).Error
+	return err == nil
+}
+
+func (r *ProductRepository) checkIfCategoryExists(id uint) bool {
+	var category entity.Category
+	err := r.db.Select("id").First(&category, "id = ?", id).Error
+	return err == nil
+}
+
+func (r *ProductRepository) GetProductByID(id string) (*response.ProductResponse, error) {
+	var product entity.Product
+	if err := r.db.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position, id")
+	}).Preload("Images.Renditions").First(&product, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ProductNotFound
+		}
+		return nil, err
+	}
+	return toProductResponse(&product), nil
+}
+
+// ResolveProductSlug returns the ID and current slug of the product addressed
+// by slug, which may be one it used before a rename.
+func (r *ProductRepository) ResolveProductSlug(slug string) (uint, string, error) {
+	id, current, err := resolveSlug(r.db, "products", entity.SlugEntityProduct, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", pkg.ProductNotFound
+	}
+	return id, current, err
+}
+
+func (r *ProductRepository) AddProduct(product *request.ProductRequest) (*response.ProductResponse, error) {
+	if !r.checkIfCategoryExists(product.CategoryID) {
+		return nil, pkg.CategoryNotFound
+	}
+	p := entity.Product{
+		Name:        product.Name,
+		Description: product.Description,
+		CategoryID:  product.CategoryID,
+		Price:       product.Price,
+		CreatedBy:   product.CreatedBy,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		slug, err := uniqueSlug(tx, "products", entity.SlugEntityProduct, product.Name, 0)
+		if err != nil {
+			return err
+		}
+		p.Slug = slug
+		if err := tx.Create(&p).Error; err != nil {
+			return err
+		}
+		return dropSlugRedirect(tx, entity.SlugEntityProduct, slug)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Product already exists")
+		}
+		return nil, err
+	}
+	return toProductResponse(&p), nil
+}
+
+func (r *ProductRepository) UpdateProduct(id string, product *request.ProductPatchRequest) (
+	*response.ProductResponse, error) {
+	updates := map[string]interface{}{}
+	if product.Name != nil {
+		updates["name"] = *product.Name
+	}
+	if product.Description != nil {
+		updates["description"] = *product.Description
+	}
+	if product.CategoryID != nil {
+		updates["category_id"] = *product.CategoryID
+	}
+	if product.Price != nil {
+		updates["price_amount"] = product.Price.Amount
+		updates["price_currency"] = product.Price.Currency
+	}
+	if len(updates) == 0 {
+		return nil, pkg.NoFieldsToUpdate
+	}
+	var existing entity.Product
+	if err := r.db.Select("id, name, slug").First(&existing, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ProductNotFound
+		}
+		return nil, err
+	}
+	if product.CategoryID != nil && !r.checkIfCategoryExists(*product.CategoryID) {
+		return nil, pkg.CategoryNotFound
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if product.Name != nil && *product.Name != existing.Name {
+			if err := renameProductSlug(tx, &existing, *product.Name); err != nil {
+				return err
+			}
+		}
+		if product.Price != nil {
+			// Variants are priced in the currency of their product, so it can
+			// only change while no variant uses another one.
+			var mismatched int64
+			if err := tx.Unscoped().Model(&entity.Variant{}).
+				Where("product_id = ? AND price_currency <> ?", existing.ID, product.Price.Currency).
+				Count(&mismatched).Error; err != nil {
+				return err
+			}
+			if mismatched > 0 {
+				return pkg.CurrencyMismatch.Withf("Product has variants priced in another currency")
+			}
+		}
+		return tx.Model(&entity.Product{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Product already exists")
+		}
+		return nil, err
+	}
+
+	return r.GetProductByID(id)
+}
+
+// DeleteProduct soft-deletes a product together with its live variants, which
+// frees their SKUs and takes them out of the variant and stock endpoints. It
+// refuses while any of those variants holds active reservations.
+func (r *ProductRepository) DeleteProduct(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product entity.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&product, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ProductNotFound
+			}
+			return err
+		}
+
+		var variantIDs []uint
+		if err := tx.Model(&entity.Variant{}).
+			Where("product_id = ?", product.ID).
+			Order("id").
+			Pluck("id", &variantIDs).Error; err != nil {
+			return err
+		}
+		if len(variantIDs) > 0 {
+			// Reserving locks the same stock rows, so no reservation can be
+			// taken between this check and the delete.
+			if err := lockStockRows(tx, variantIDs); err != nil {
+				return err
+			}
+			var active int64
+			if err := tx.Model(&entity.StockReservation{}).
+				Where("variant_id IN ? AND status = ?", variantIDs, entity.ReservationStatusActive).
+				Count(&active).Error; err != nil {
+				return err
+			}
+			if active > 0 {
+				return pkg.ProductHasReservations
+			}
+		}
+
+		// The variants share the deletion time of their product, which is how
+		// RestoreProduct tells them from variants deleted on their own.
+		now := tx.NowFunc()
+		if len(variantIDs) > 0 {
+			if err := tx.Model(&entity.Variant{}).
+				Where("id IN ?", variantIDs).
+				Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&entity.Product{}).Where("id = ?", product.ID).Update("deleted_at", now).Error
+	})
+}
+
+// RestoreProduct undeletes a product whose category is still live, along with
+// the variants deleted with it. It gets a fresh slug if a live product took
+// its old one in the meantime.
+func (r *ProductRepository) RestoreProduct(id string) (*response.ProductResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		product, err := findDeletedProduct(tx, id)
+		if err != nil || !product.DeletedAt.Valid {
+			return err
+		}
+		if !r.checkIfCategoryExists(product.CategoryID) {
+			return pkg.ProductCategoryDeleted
+		}
+
+		if err := tx.Unscoped().Model(&entity.Variant{}).
+			Where("product_id = ? AND deleted_at = ?", product.ID, product.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		slug, err := restoredSlug(tx, "products", entity.SlugEntityProduct, product.Name, product.Slug, product.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&entity.Product{}).
+			Where("id = ?", product.ID).
+			Updates(map[string]interface{}{"deleted_at": nil, "slug": slug}).Error; err != nil {
+			return err
+		}
+		return dropSlugRedirect(tx, entity.SlugEntityProduct, slug)
+	})
+	if err != nil {
+		// The slug never conflicts, so a unique violation is a variant whose
+		// SKU was taken while it was deleted.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Variant SKU already exists")
+		}
+		return nil, err
+	}
+	return r.GetProductByID(id)
+}
+
+// PurgeProduct permanently removes a soft-deleted product together with its
+// variants and images. The image rows are returned with their renditions so
+// the caller can delete the blobs once the transaction has committed.
+func (r *ProductRepository) PurgeProduct(id string) ([]entity.ProductImage, error) {
+	var images []entity.ProductImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		product, err := findDeletedProduct(tx, id)
+		if err != nil {
+			return err
+		}
+		if !product.DeletedAt.Valid {
+			return pkg.RecordNotDeleted
+		}
+
+		if err := tx.Unscoped().Preload("Renditions", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).Where("product_id = ?", product.ID).Find(&images).Error; err != nil {
+			return err
+		}
+		if len(images) > 0 {
+			imageIDs := make([]uint, 0, len(images))
+			for _, img := range images {
+				imageIDs = append(imageIDs, img.ID)
+			}
+			if err := tx.Unscoped().Where("product_image_id IN ?", imageIDs).
+				Delete(&entity.ProductImageRendition{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("id IN ?", imageIDs).Delete(&entity.ProductImage{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var variants []entity.Variant
+		if err := tx.Unscoped().Where("product_id = ?", product.ID).Find(&variants).Error; err != nil {
+			return err
+		}
+		for i := range variants {
+			if err := purgeVariant(tx, &variants[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := deleteSlugHistory(tx, entity.SlugEntityProduct, product.ID); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&entity.Product{}, product.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// findDeletedProduct loads a product whether or not it is soft-deleted.
+func findDeletedProduct(tx *gorm.DB, id string) (*entity.Product, error) {
+	var product entity.Product
+	if err := tx.Unscoped().First(&product, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.ProductNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// preloadPrimaryImage loads just the primary image and its renditions, which
+// is all listing grids need.
+func preloadPrimaryImage(db *gorm.DB) *gorm.DB {
+	return db.Preload("Images", "is_primary").Preload("Images.Renditions")
+}
+
+func toProductResponse(p *entity.Product) *response.ProductResponse {
+	res := &response.ProductResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Slug:        p.Slug,
+		Description: p.Description,
+		CategoryID:  p.CategoryID,
+		Price:       p.Price,
+		CreatedBy:   p.CreatedBy,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		DeletedAt:   deletedAt(p.DeletedAt),
+	}
+	if len(p.Images) > 0 {
+		res.Images = toProductImageResponses(p.Images)
+	}
+	return res
+}
+
+func toProductResponses(products []entity.Product) []response.ProductResponse {
+	res := make([]response.ProductResponse, 0, len(products))
+	for i := range products {
+		res = append(res, *toProductResponse(&products[i]))
+	}
+	return res
+}
+
+// renameProductSlug derives a new slug from the new name and keeps the old
+// one as a redirect.
+func renameProductSlug(tx *gorm.DB, product *entity.Product, name string) error {
+	slug, err := uniqueSlug(tx, "products", entity.SlugEntityProduct, name, product.ID)
+	if err != nil {
+		return err
+	}
+	if slug == product.Slug {
+		return nil
+	}
+	if err := tx.Model(&entity.Product{}).Where("id = ?", product.ID).Update("slug", slug).Error; err != nil {
+		return err
+	}
+	if err := dropSlugRedirect(tx, entity.SlugEntityProduct, slug); err != nil {
+		return err
+	}
+	return addSlugRedirect(tx, entity.SlugEntityProduct, product.ID, product.Slug)
+}
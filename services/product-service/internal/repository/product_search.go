@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/response"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var searchTerm = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// ts_headline options: matches are wrapped in <mark> and descriptions are cut
+// down to a couple of short fragments.
+const (
+	nameHeadlineOptions        = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	descriptionHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+// productMatch is the predicate for a search: the product's own text, its
+// category name or any of its variant SKUs. @q is a prefix tsquery.
+const productMatch = `(p.search_vector @@ to_tsquery('english', @q)
+	OR c.search_vector @@ to_tsquery('english', @q)
+	OR EXISTS (
+		SELECT 1 FROM variants v
+		WHERE v.product_id = p.id AND v.deleted_at IS NULL
+			AND v.search_vector @@ to_tsquery('simple', @q)
+	))`
+
+// productRank weights product text fully, the category name at half and the
+// best matching SKU at full weight.
+const productRank = `ts_rank(p.search_vector, to_tsquery('english', @q))
+	+ 0.5 * ts_rank(c.search_vector, to_tsquery('english', @q))
+	+ COALESCE((
+		SELECT max(ts_rank(v.search_vector, to_tsquery('simple', @q))) FROM variants v
+		WHERE v.product_id = p.id AND v.deleted_at IS NULL
+	), 0)`
+
+type searchHit struct {
+	ID           uint
+	CategoryName string
+	Rank         float64
+}
+
+type searchHighlight struct {
+	ID                 uint
+	NameHighlight      string
+	DescriptionSnippet string
+	MatchedSKUs        string
+}
+
+// SearchProducts ranks products against a free-text query using the
+// generated search_vector columns. Every word is matched as a prefix so
+// partial input works for typeahead.
+func (r *ProductRepository) SearchProducts(term string, q *pkg.ListQuery) ([]response.ProductSearchResponse, int64, error) {
+	tsq := toPrefixTSQuery(term)
+	if tsq == "" {
+		return nil, 0, pkg.EmptySearchQuery
+	}
+	arg := sql.Named("q", tsq)
+	query := r.searchQuery(tsq, q)
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var hits []searchHit
+	if err := query.Select("p.id, c.name AS category_name, "+productRank+" AS rank", arg).
+		Order("rank DESC, p.id").
+		Limit(q.Limit).
+		Offset(q.Offset).
+		Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return []response.ProductSearchResponse{}, total, nil
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+
+	var products []entity.Product
+	if err := preloadPrimaryImage(r.db).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]*entity.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	// Headlines are comparatively expensive, so only compute them for the
+	// rows on this page.
+	var highlights []searchHighlight
+	if err := r.db.Table("products p").
+		Select(`p.id,
+			ts_headline('english', p.name, to_tsquery('english', @q), @nameOpts) AS name_highlight,
+			ts_headline('english', coalesce(p.description, ''), to_tsquery('english', @q), @descOpts)
+				AS description_snippet,
+			COALESCE((
+				SELECT json_agg(v.sku ORDER BY v.sku) FROM variants v
+				WHERE v.product_id = p.id AND v.deleted_at IS NULL
+					AND v.search_vector @@ to_tsquery('simple', @q)
+			), '[]') AS matched_skus`,
+			arg, sql.Named("nameOpts", nameHeadlineOptions), sql.Named("descOpts", descriptionHeadlineOptions)).
+		Where("p.id IN ?", ids).
+		Scan(&highlights).Error; err != nil {
+		return nil, 0, err
+	}
+	highlightByID := make(map[uint]searchHighlight, len(highlights))
+	for _, h := range highlights {
+		highlightByID[h.ID] = h
+	}
+
+	results := make([]response.ProductSearchResponse, 0, len(hits))
+	for _, hit := range hits {
+		product, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		h := highlightByID[hit.ID]
+		result := response.ProductSearchResponse{
+			ProductResponse:    *toProductResponse(product),
+			CategoryName:       hit.CategoryName,
+			Rank:               hit.Rank,
+			NameHighlight:      h.NameHighlight,
+			DescriptionSnippet: h.DescriptionSnippet,
+		}
+		if h.MatchedSKUs != "" {
+			if err := json.Unmarshal([]byte(h.MatchedSKUs), &result.MatchedSKUs); err != nil {
+				return nil, 0, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, total, nil
+}
+
+// searchQuery selects the products matching tsq and the filters in q, with
+// products aliased as p and their live category as c.
+func (r *ProductRepository) searchQuery(tsq string, q *pkg.ListQuery) *gorm.DB {
+	return q.Filter(r.db.Table("products p").
+		Joins("JOIN categories c ON c.id = p.category_id AND c.deleted_at IS NULL").
+		Where("p.deleted_at IS NULL").
+		Where(productMatch, sql.Named("q", tsq)))
+}
+
+// toPrefixTSQuery turns free text into a tsquery matching every word as a
+// prefix, e.g. "red shi" becomes "red:* & shi:*". Only letters and digits
+// are kept, so the result is always valid tsquery syntax.
+func toPrefixTSQuery(term string) string {
+	words := searchTerm.FindAllString(strings.ToLower(term), -1)
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"errors"
+	"maps"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Stock is held by raising WarehouseStock.Reserved on the rows chosen by
+// allocateStock. Reserving, committing and releasing all lock the stock rows
+// of their variants in ID order first, so concurrent checkouts of the same
+// variant serialize without deadlocking and a reservation only succeeds while
+// Quantity - Reserved covers it. The chk_warehouse_stocks_reserved constraint
+// backs this up in the database. Every hold, sale and release is a ledger
+// movement referencing the order.
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// Reserve holds stock for every item of an order until expiresAt, drawing on
+// warehouses in the order of the requested allocation rule. An item that no
+// single warehouse can cover is split across several, one reservation each.
+// Either all items are reserved or none are.
+func (r *ReservationRepository) Reserve(reservation *request.ReservationRequest, expiresAt time.Time,
+	actor string) ([]response.ReservationResponse, error) {
+	quantities := make(map[uint]int, len(reservation.Items))
+	for _, item := range reservation.Items {
+		quantities[item.VariantID] += item.Quantity
+	}
+
+	var reservations []entity.StockReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockOrder(tx, reservation.OrderRef); err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&entity.StockReservation{}).
+			Where("order_ref = ? AND status = ?", reservation.OrderRef, entity.ReservationStatusActive).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return pkg.OrderAlreadyReserved
+		}
+
+		variantIDs := slices.Sorted(maps.Keys(quantities))
+		if err := lockStockRows(tx, variantIDs); err != nil {
+			return err
+		}
+		for _, variantID := range variantIDs {
+			allocations, err := allocateStock(tx, variantID, quantities[variantID],
+				reservation.Allocation, reservation.Latitude, reservation.Longitude)
+			if err != nil {
+				return err
+			}
+			for _, a := range allocations {
+				if err := moveStock(tx, &entity.StockMovement{
+					VariantID:     variantID,
+					WarehouseID:   a.WarehouseID,
+					Type:          entity.StockMovementReservation,
+					ReservedDelta: a.Quantity,
+					Reason:        "Reserved",
+					Actor:         actor,
+					Reference:     reservation.OrderRef,
+				}); err != nil {
+					return err
+				}
+				reservations = append(reservations, entity.StockReservation{
+					OrderRef:    reservation.OrderRef,
+					VariantID:   variantID,
+					WarehouseID: &a.WarehouseID,
+					Quantity:    a.Quantity,
+					Status:      entity.ReservationStatusActive,
+					ExpiresAt:   expiresAt,
+				})
+			}
+		}
+		if err := tx.Create(&reservations).Error; err != nil {
+			return err
+		}
+		return syncVariantStock(tx, variantIDs...)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.OrderAlreadyReserved
+		}
+		return nil, err
+	}
+	return toReservationResponses(reservations), nil
+}
+
+// orderLockSpace namespaces the transaction-level advisory locks taken per
+// order, keyed by a hash of the order reference.
+const orderLockSpace = 7_401_002
+
+// lockOrder serializes reserving for one order, so two concurrent requests
+// cannot both find it without active reservations.
+func lockOrder(tx *gorm.DB, orderRef string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", orderLockSpace, orderRef).Error
+}
+
+// GetReservations returns every reservation an order has made, whatever its
+// status.
+func (r *ReservationRepository) GetReservations(orderRef string) ([]response.ReservationResponse, error) {
+	var reservations []entity.StockReservation
+	if err := r.db.Where("order_ref = ?", orderRef).Order("id").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, pkg.ReservationNotFound
+	}
+	return toReservationResponses(reservations), nil
+}
+
+// Commit turns the active reservations of an order into a sale: the reserved
+// units leave both the quantity and the reserved count of their warehouse. It
+// fails if any of them has expired.
+func (r *ReservationRepository) Commit(orderRef, actor string) ([]response.ReservationResponse, error) {
+	var reservations []entity.StockReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reservations, err = lockActiveReservations(tx, orderRef); err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, res := range reservations {
+			if res.ExpiresAt.Before(now) {
+				return pkg.ReservationExpired
+			}
+		}
+
+		if err := lockStockRows(tx, reservedVariantIDs(reservations)); err != nil {
+			return err
+		}
+		for _, res := range reservations {
+			if err := moveStock(tx, &entity.StockMovement{
+				VariantID:     res.VariantID,
+				WarehouseID:   *res.WarehouseID,
+				Type:          entity.StockMovementSale,
+				Delta:         -res.Quantity,
+				ReservedDelta: -res.Quantity,
+				Reason:        "Order committed",
+				Actor:         actor,
+				Reference:     res.OrderRef,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := syncVariantStock(tx, reservedVariantIDs(reservations)...); err != nil {
+			return err
+		}
+		return setReservationStatus(tx, reservations, entity.ReservationStatusCommitted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReservationResponses(reservations), nil
+}
+
+// Release gives the stock held by the active reservations of an order back.
+func (r *ReservationRepository) Release(orderRef, actor string) ([]response.ReservationResponse, error) {
+	var reservations []entity.StockReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if reservations, err = lockActiveReservations(tx, orderRef); err != nil {
+			return err
+		}
+		return releaseReservations(tx, reservations, entity.ReservationStatusReleased, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toReservationResponses(reservations), nil
+}
+
+// ReleaseExpired releases up to limit active reservations whose TTL has
+// passed and returns how many it released. Rows locked by a concurrent commit
+// or release are skipped, so several instances can sweep at once.
+func (r *ReservationRepository) ReleaseExpired(limit int) (int, error) {
+	var expired []entity.StockReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at < ?", entity.ReservationStatusActive, time.Now()).
+			Order("variant_id, warehouse_id, id").
+			Limit(limit).
+			Find(&expired).Error; err != nil {
+			return err
+		}
+		return releaseReservations(tx, expired, entity.ReservationStatusExpired, systemActor)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// lockActiveReservations loads the active reservations of an order for
+// update, in variant order to match Reserve.
+func lockActiveReservations(tx *gorm.DB, orderRef string) ([]entity.StockReservation, error) {
+	var reservations []entity.StockReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_ref = ? AND status = ?", orderRef, entity.ReservationStatusActive).
+		Order("variant_id, warehouse_id").
+		Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, pkg.ReservationNotFound
+	}
+	return reservations, nil
+}
+
+func releaseReservations(tx *gorm.DB, reservations []entity.StockReservation, status, actor string) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	if err := lockStockRows(tx, reservedVariantIDs(reservations)); err != nil {
+		return err
+	}
+	for _, res := range reservations {
+		if err := moveStock(tx, &entity.StockMovement{
+			VariantID:     res.VariantID,
+			WarehouseID:   *res.WarehouseID,
+			Type:          entity.StockMovementReservation,
+			ReservedDelta: -res.Quantity,
+			Reason:        "Reservation " + status,
+			Actor:         actor,
+			Reference:     res.OrderRef,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := syncVariantStock(tx, reservedVariantIDs(reservations)...); err != nil {
+		return err
+	}
+	return setReservationStatus(tx, reservations, status)
+}
+
+// reservedVariantIDs returns the distinct variants the reservations hold.
+func reservedVariantIDs(reservations []entity.StockReservation) []uint {
+	ids := make([]uint, 0, len(reservations))
+	for _, res := range reservations {
+		ids = append(ids, res.VariantID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func setReservationStatus(tx *gorm.DB, reservations []entity.StockReservation, status string) error {
+	ids := make([]uint, 0, len(reservations))
+	for _, res := range reservations {
+		ids = append(ids, res.ID)
+	}
+	if err := tx.Model(&entity.StockReservation{}).
+		Where("id IN ?", ids).
+		Update("status", status).Error; err != nil {
+		return err
+	}
+	for i := range reservations {
+		reservations[i].Status = status
+	}
+	return nil
+}
+
+func toReservationResponses(reservations []entity.StockReservation) []response.ReservationResponse {
+	res := make([]response.ReservationResponse, 0, len(reservations))
+	for _, r := range reservations {
+		res = append(res, response.ReservationResponse{
+			ID:          r.ID,
+			OrderRef:    r.OrderRef,
+			VariantID:   r.VariantID,
+			WarehouseID: r.WarehouseID,
+			Quantity:    r.Quantity,
+			Status:      r.Status,
+			ExpiresAt:   r.ExpiresAt,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+		})
+	}
+	return res
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"fmt"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type slugOwner struct {
+	ID   uint
+	Name string
+	Slug string
+}
+
+// reservedSlugs holds, per kind, the static route segments that sit where a
+// slug would go, e.g. /categories/tree. The router matches those first, so a
+// slug equal to one of them could never be looked up.
+var reservedSlugs = map[string][]string{
+	entity.SlugEntityCategory: {"tree", "order"},
+	entity.SlugEntityProduct:  {"search"},
+}
+
+// uniqueSlug derives a slug from name that no other live row of table uses,
+// appending -2, -3, ... on collisions; reserved slugs count as taken. Names
+// that give an empty or purely numeric slug are prefixed with kind to keep
+// slugs distinguishable from IDs.
+func uniqueSlug(tx *gorm.DB, table, kind, name string, excludeID uint) (string, error) {
+	base := pkg.Slugify(name)
+	if base == "" || pkg.IsNumericID(base) {
+		base = strings.TrimSuffix(kind+"-"+base, "-")
+	}
+
+	// Slugs only contain [a-z0-9-], so base needs no LIKE escaping.
+	var taken []string
+	if err := tx.Table(table).
+		Where("(slug = ? OR slug LIKE ?) AND id <> ? AND deleted_at IS NULL", base, base+"-%", excludeID).
+		Pluck("slug", &taken).Error; err != nil {
+		return "", err
+	}
+	used := make(map[string]bool, len(taken))
+	for _, s := range append(taken, reservedSlugs[kind]...) {
+		used[s] = true
+	}
+
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug, nil
+}
+
+// restoredSlug returns the slug a restored row should use: its old one,
+// unless a live row took it while it was deleted or it has become reserved.
+func restoredSlug(tx *gorm.DB, table, kind, name, slug string, id uint) (string, error) {
+	var taken int64
+	if err := tx.Table(table).
+		Where("slug = ? AND id <> ? AND deleted_at IS NULL", slug, id).
+		Count(&taken).Error; err != nil {
+		return "", err
+	}
+	if slug != "" && taken == 0 && !slices.Contains(reservedSlugs[kind], slug) {
+		return slug, nil
+	}
+	return uniqueSlug(tx, table, kind, name, id)
+}
+
+// deleteSlugHistory removes every redirect to an entity that is being purged.
+func deleteSlugHistory(tx *gorm.DB, kind string, entityID uint) error {
+	return tx.Unscoped().
+		Where("entity_type = ? AND entity_id = ?", kind, entityID).
+		Delete(&entity.SlugHistory{}).Error
+}
+
+// moveSlugHistory hands every redirect to one entity over to another, e.g.
+// when the first is merged into the second.
+func moveSlugHistory(tx *gorm.DB, kind string, fromID, toID uint) error {
+	return tx.Model(&entity.SlugHistory{}).
+		Where("entity_type = ? AND entity_id = ?", kind, fromID).
+		Update("entity_id", toID).Error
+}
+
+// addSlugRedirect keeps a slug an entity no longer uses so requests for it
+// can be redirected to the entity's current slug.
+func addSlugRedirect(tx *gorm.DB, kind string, entityID uint, slug string) error {
+	if slug == "" {
+		return nil
+	}
+	return tx.Create(&entity.SlugHistory{EntityType: kind, EntityID: entityID, Slug: slug}).Error
+}
+
+// dropSlugRedirect removes the redirect for a slug that has become live
+// again, for the same or another entity; the live slug always wins.
+func dropSlugRedirect(tx *gorm.DB, kind, slug string) error {
+	return tx.Unscoped().
+		Where("entity_type = ? AND slug = ?", kind, slug).
+		Delete(&entity.SlugHistory{}).Error
+}
+
+// resolveSlug finds the live entity of table addressed by slug, either
+// directly or through its slug history, and returns its ID and current slug.
+// The two slugs differ when the caller should redirect.
+func resolveSlug(db *gorm.DB, table, kind, slug string) (uint, string, error) {
+	var owner slugOwner
+	if err := db.Table(table).
+		Select("id, slug").
+		Where("slug = ? AND deleted_at IS NULL", slug).
+		Limit(1).
+		Scan(&owner).Error; err != nil {
+		return 0, "", err
+	}
+	if owner.ID != 0 {
+		return owner.ID, owner.Slug, nil
+	}
+
+	if err := db.Table(table+" t").
+		Select("t.id, t.slug").
+		Joins("JOIN slug_histories h ON h.entity_id = t.id AND h.deleted_at IS NULL").
+		Where("h.entity_type = ? AND h.slug = ? AND t.deleted_at IS NULL", kind, slug).
+		Limit(1).
+		Scan(&owner).Error; err != nil {
+		return 0, "", err
+	}
+	if owner.ID == 0 {
+		return 0, "", gorm.ErrRecordNotFound
+	}
+	return owner.ID, owner.Slug, nil
+}
+
+// AssignMissingSlugs gives every category and product created before slugs
+// existed, or holding a slug that has since become reserved, a slug derived
+// from its name.
+func AssignMissingSlugs(db *gorm.DB) error {
+	tables := []struct{ table, kind string }{
+		{"categories", entity.SlugEntityCategory},
+		{"products", entity.SlugEntityProduct},
+	}
+	for _, t := range tables {
+		var owners []slugOwner
+		if err := db.Table(t.table).
+			Select("id, name").
+			Where("slug IS NULL OR slug = '' OR slug IN ?", reservedSlugs[t.kind]).
+			Order("id").
+			Scan(&owners).Error; err != nil {
+			return err
+		}
+		for _, o := range owners {
+			slug, err := uniqueSlug(db, t.table, t.kind, o.Name, o.ID)
+			if err != nil {
+				return err
+			}
+			if err := db.Table(t.table).Where("id = ?", o.ID).Update("slug", slug).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
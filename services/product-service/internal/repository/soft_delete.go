@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// deletedAt exposes a soft-delete timestamp on responses; it is nil for live
+// rows.
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
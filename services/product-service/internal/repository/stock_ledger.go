@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+
+	"gorm.io/gorm"
+)
+
+// systemActor is recorded on movements the service makes on its own, such as
+// releasing expired reservations.
+const systemActor = "system"
+
+const openingBalanceReason = "Opening balance"
+
+// GetStockHistory lists the ledger of a variant, soft-deleted or not, with
+// the quantity on hand after each movement.
+func (r *VariantRepository) GetStockHistory(variantID string, q *pkg.ListQuery) (
+	[]response.StockMovementResponse, int64, error) {
+	var variant entity.Variant
+	if err := r.db.Unscoped().Select("id").First(&variant, "id = ?", variantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, pkg.VariantNotFound
+		}
+		return nil, 0, err
+	}
+
+	// The running balance is computed over the whole ledger before any
+	// filter or page applies.
+	ledger := r.db.Model(&entity.StockMovement{}).
+		Select("*, sum(delta) OVER (ORDER BY id) AS balance").
+		Where("variant_id = ?", variant.ID)
+	query := q.Filter(r.db.Table("(?) AS m", ledger))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var movements []response.StockMovementResponse
+	if err := q.Page(query).Scan(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+// AddStockMovement records stock received, returned or adjusted in a
+// warehouse.
+func (r *VariantRepository) AddStockMovement(variantID string, movement *request.StockMovementRequest,
+	actor string) (*response.StockMovementResponse, error) {
+	if movement.Type != entity.StockMovementAdjustment && movement.Delta < 0 {
+		return nil, pkg.InvalidStockMovement
+	}
+
+	var created entity.StockMovement
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var variant entity.Variant
+		if err := tx.Select("id").First(&variant, "id = ?", variantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.VariantNotFound
+			}
+			return err
+		}
+		warehouseID, err := resolveWarehouseID(tx, movement.WarehouseID)
+		if err != nil {
+			return err
+		}
+
+		created = entity.StockMovement{
+			VariantID:   variant.ID,
+			WarehouseID: warehouseID,
+			Type:        movement.Type,
+			Delta:       movement.Delta,
+			Reason:      movement.Reason,
+			Actor:       actor,
+			Reference:   movement.Reference,
+		}
+		if err := moveStock(tx, &created); err != nil {
+			return err
+		}
+		return syncVariantStock(tx, variant.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &toStockMovementResponses([]entity.StockMovement{created})[0], nil
+}
+
+// BackfillStockLedger records an opening balance for every warehouse stock
+// row the ledger does not account for, so that the ledger sums match the
+// stock rows from then on. It is meant to run once, when the ledger is
+// introduced; afterwards such differences are drift for reconciliation to
+// report rather than hide.
+func BackfillStockLedger(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO stock_movements
+			(variant_id, warehouse_id, type, delta, reserved_delta, reason, actor, reference, created_at)
+		SELECT s.variant_id, s.warehouse_id, ?, s.quantity - coalesce(m.delta, 0),
+			s.reserved - coalesce(m.reserved, 0), ?, ?, '', now()
+		FROM warehouse_stocks s
+		LEFT JOIN (
+			SELECT variant_id, warehouse_id, sum(delta) AS delta, sum(reserved_delta) AS reserved
+			FROM stock_movements
+			GROUP BY variant_id, warehouse_id
+		) m ON m.variant_id = s.variant_id AND m.warehouse_id = s.warehouse_id
+		WHERE s.quantity <> coalesce(m.delta, 0) OR s.reserved <> coalesce(m.reserved, 0)`,
+		entity.StockMovementAdjustment, openingBalanceReason, systemActor).Error
+}
+
+// FindStockDrift returns every variant, soft-deleted ones included, whose
+// stock or reserved total differs from the sum of its ledger.
+func FindStockDrift(db *gorm.DB) ([]response.StockDriftResponse, error) {
+	var drift []response.StockDriftResponse
+	err := db.Raw(`
+		SELECT v.id AS variant_id, v.sku, v.stock, coalesce(m.delta, 0) AS ledger_stock,
+			v.reserved, coalesce(m.reserved, 0) AS ledger_reserved
+		FROM variants v
+		LEFT JOIN (
+			SELECT variant_id, sum(delta) AS delta, sum(reserved_delta) AS reserved
+			FROM stock_movements
+			GROUP BY variant_id
+		) m ON m.variant_id = v.id
+		WHERE v.stock <> coalesce(m.delta, 0) OR v.reserved <> coalesce(m.reserved, 0)
+		ORDER BY v.id`).Scan(&drift).Error
+	return drift, err
+}
+
+// RebuildStockFromLedger resets every warehouse stock row to the sums of its
+// ledger, zero for rows without movements, then derives the variant totals
+// from the rows again through syncVariantStock, queueing the notifications
+// the corrections call for. It returns how many variant totals it corrected.
+func RebuildStockFromLedger(db *gorm.DB) (int64, error) {
+	const ledgerSums = `
+		SELECT variant_id, warehouse_id, sum(delta) AS delta, sum(reserved_delta) AS reserved
+		FROM stock_movements
+		GROUP BY variant_id, warehouse_id`
+
+	var corrected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// moveStock updates a warehouse row and records the movement in the
+		// same transaction, so shutting out writes to the rows keeps the
+		// ledger from moving under the sums below. Changes already under way
+		// commit first.
+		if err := tx.Exec("LOCK TABLE warehouse_stocks IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			UPDATE warehouse_stocks s
+			SET quantity = coalesce(m.delta, 0), reserved = coalesce(m.reserved, 0), updated_at = now()
+			FROM warehouse_stocks x
+			LEFT JOIN (` + ledgerSums + `) m ON m.variant_id = x.variant_id AND m.warehouse_id = x.warehouse_id
+			WHERE s.id = x.id
+				AND (s.quantity <> coalesce(m.delta, 0) OR s.reserved <> coalesce(m.reserved, 0))`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			INSERT INTO warehouse_stocks (variant_id, warehouse_id, quantity, reserved, created_at, updated_at)
+			SELECT m.variant_id, m.warehouse_id, m.delta, m.reserved, now(), now()
+			FROM (` + ledgerSums + `) m
+			WHERE NOT EXISTS (SELECT 1 FROM warehouse_stocks s
+				WHERE s.variant_id = m.variant_id AND s.warehouse_id = m.warehouse_id)`).Error; err != nil {
+			return err
+		}
+		var drifted []uint
+		if err := tx.Raw(`
+			SELECT v.id
+			FROM variants v
+			LEFT JOIN (
+				SELECT variant_id, sum(quantity) AS quantity, sum(reserved) AS reserved
+				FROM warehouse_stocks
+				GROUP BY variant_id
+			) s ON s.variant_id = v.id
+			WHERE v.stock <> coalesce(s.quantity, 0) OR v.reserved <> coalesce(s.reserved, 0)
+			ORDER BY v.id`).Scan(&drifted).Error; err != nil {
+			return err
+		}
+		corrected = int64(len(drifted))
+		return syncVariantStock(tx, drifted...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return corrected, nil
+}
+
+func toStockMovementResponses(movements []entity.StockMovement) []response.StockMovementResponse {
+	res := make([]response.StockMovementResponse, 0, len(movements))
+	for _, m := range movements {
+		res = append(res, response.StockMovementResponse{
+			ID:            m.ID,
+			VariantID:     m.VariantID,
+			WarehouseID:   m.WarehouseID,
+			Type:          m.Type,
+			Delta:         m.Delta,
+			ReservedDelta: m.ReservedDelta,
+			Reason:        m.Reason,
+			Actor:         m.Actor,
+			Reference:     m.Reference,
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	return res
+}
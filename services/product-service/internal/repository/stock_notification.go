@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"product-service/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxNotificationAttempts is how many times a notification is tried before it
+// is marked failed; notificationRetryDelay is the wait after the first failed
+// attempt, doubled after each further one. notificationLease is how long a
+// claimed notification is left to its dispatcher before it is tried again.
+const (
+	maxNotificationAttempts = 5
+	notificationRetryDelay  = time.Minute
+	notificationLease       = 5 * time.Minute
+)
+
+// stockTransition is the stock of a variant before and after a change.
+type stockTransition struct {
+	ID               uint
+	SKU              string
+	OldStock         int
+	OldReserved      int
+	Stock            int
+	Reserved         int
+	ReorderThreshold *int
+	DeletedAt        gorm.DeletedAt
+}
+
+// queueStockNotifications queues a low-stock notification for every live
+// variant whose available stock fell below its reorder threshold, and a
+// back-in-stock notification for every waiting subscription to a live
+// variant whose available stock went from zero to positive. Notified
+// subscriptions stop waiting.
+func queueStockNotifications(tx *gorm.DB, transitions []stockTransition) error {
+	now := time.Now()
+	var notifications []entity.StockNotification
+	var notified []uint
+	for _, t := range transitions {
+		if t.DeletedAt.Valid {
+			continue
+		}
+
+		oldAvailable, available := t.OldStock-t.OldReserved, t.Stock-t.Reserved
+		if t.ReorderThreshold != nil && oldAvailable >= *t.ReorderThreshold && available < *t.ReorderThreshold {
+			notifications = append(notifications, entity.StockNotification{
+				Type:      entity.StockNotificationLowStock,
+				VariantID: t.ID,
+				SKU:       t.SKU,
+				Available: available,
+				Threshold: t.ReorderThreshold,
+				SendAfter: now,
+			})
+		}
+
+		if oldAvailable <= 0 && available > 0 {
+			var subscriptions []entity.StockSubscription
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("variant_id = ? AND notified_at IS NULL", t.ID).
+				Order("id").
+				Find(&subscriptions).Error; err != nil {
+				return err
+			}
+			for _, sub := range subscriptions {
+				notifications = append(notifications, entity.StockNotification{
+					Type:           entity.StockNotificationBackInStock,
+					VariantID:      t.ID,
+					SKU:            t.SKU,
+					Available:      available,
+					Recipient:      sub.Email,
+					SubscriptionID: &sub.ID,
+					SendAfter:      now,
+				})
+				notified = append(notified, sub.ID)
+			}
+		}
+	}
+
+	if len(notified) > 0 {
+		if err := tx.Model(&entity.StockSubscription{}).
+			Where("id IN ?", notified).
+			Update("notified_at", now).Error; err != nil {
+			return err
+		}
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.Create(&notifications).Error
+}
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// DeliverPending passes up to limit due notifications to send and records the
+// outcome of each: sent, retried later with a growing delay, or failed after
+// maxNotificationAttempts. It returns how many notifications it handled.
+//
+// The notifications are claimed in a short transaction that leases them for
+// notificationLease, so several dispatchers can deliver at once and send runs
+// without holding locks. A dispatcher that dies mid-batch leaves its claims
+// to be retried once the lease runs out.
+func (r *NotificationRepository) DeliverPending(limit int, send func(entity.StockNotification) error) (int, error) {
+	due, err := r.claimDue(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, n := range due {
+		updates := map[string]interface{}{}
+		if err := send(n); err != nil {
+			updates["last_error"] = err.Error()
+			if n.Attempts >= maxNotificationAttempts {
+				updates["status"] = entity.NotificationStatusFailed
+			} else {
+				updates["send_after"] = time.Now().Add(notificationRetryDelay << (n.Attempts - 1))
+			}
+		} else {
+			updates["status"] = entity.NotificationStatusSent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+		}
+		if err := r.db.Model(&entity.StockNotification{}).Where("id = ?", n.ID).Updates(updates).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+// claimDue leases up to limit due pending notifications, counting the
+// attempt about to be made.
+func (r *NotificationRepository) claimDue(limit int) ([]entity.StockNotification, error) {
+	var due []entity.StockNotification
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND send_after <= ?", entity.NotificationStatusPending, time.Now()).
+			Order("send_after, id").
+			Limit(limit).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(due))
+		for i := range due {
+			due[i].Attempts++
+			ids = append(ids, due[i].ID)
+		}
+		return tx.Model(&entity.StockNotification{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"send_after": time.Now().Add(notificationLease),
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return due, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewStockSubscriptionRepository(db *gorm.DB) *StockSubscriptionRepository {
+	return &StockSubscriptionRepository{db: db}
+}
+
+// Subscribe registers an email for a back-in-stock notification of a variant
+// with no stock available, reserved units not counting.
+func (r *StockSubscriptionRepository) Subscribe(subscription *request.StockSubscriptionRequest) (
+	*response.StockSubscriptionResponse, error) {
+	var res *response.StockSubscriptionResponse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// The share lock keeps stock from arriving between the check and the
+		// insert, which would leave the subscription waiting for the next
+		// restock.
+		var variant entity.Variant
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+			Select("id, sku, stock, reserved").
+			First(&variant, "sku = ?", subscription.SKU).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.VariantNotFound.Withf("Variant %s not found", subscription.SKU)
+			}
+			return err
+		}
+		if variant.Stock-variant.Reserved > 0 {
+			return pkg.VariantInStock
+		}
+
+		created := entity.StockSubscription{
+			VariantID: variant.ID,
+			Email:     strings.ToLower(subscription.Email),
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return err
+		}
+		res = toStockSubscriptionResponse(&created, variant.SKU)
+		return nil
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Email is already subscribed to this variant")
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *StockSubscriptionRepository) GetSubscription(id string) (*response.StockSubscriptionResponse, error) {
+	var res response.StockSubscriptionResponse
+	if err := r.db.Table("stock_subscriptions s").
+		Select("s.id, s.variant_id, v.sku, s.email, s.notified_at, s.created_at").
+		Joins("JOIN variants v ON v.id = s.variant_id").
+		Where("s.id = ? AND s.deleted_at IS NULL", id).
+		Take(&res).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.StockSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Unsubscribe soft-deletes a subscription, so no notification is queued for
+// it any more.
+func (r *StockSubscriptionRepository) Unsubscribe(id string) error {
+	res := r.db.Delete(&entity.StockSubscription{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return pkg.StockSubscriptionNotFound
+	}
+	return nil
+}
+
+func toStockSubscriptionResponse(s *entity.StockSubscription, sku string) *response.StockSubscriptionResponse {
+	return &response.StockSubscriptionResponse{
+		ID:         s.ID,
+		VariantID:  s.VariantID,
+		SKU:        sku,
+		Email:      s.Email,
+		NotifiedAt: s.NotifiedAt,
+		CreatedAt:  s.CreatedAt,
+	}
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxMatrixVariants caps how many rows a single generate request may produce.
+const maxMatrixVariants = 500
+
+var skuPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+type matrixAxis struct {
+	attribute entity.Attribute
+	values    []entity.AttributeValue
+}
+
+// GenerateVariants builds the cartesian product of the requested attribute
+// axes and creates one variant per combination in a single transaction.
+// Combinations that already exist on the product are reported as skipped.
+// With DryRun set the rows are computed and returned but nothing is written.
+func (r *VariantRepository) GenerateVariants(productID string, matrix *request.VariantMatrixRequest, actor string) (
+	*response.VariantMatrixResponse, error) {
+	result := &response.VariantMatrixResponse{
+		DryRun:   matrix.DryRun,
+		Variants: make([]response.VariantResponse, 0),
+		Skipped:  make([]response.VariantResponse, 0),
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// The lock keeps a concurrent create from adding a combination
+		// between the existing-key check and the insert.
+		var product entity.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", productID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.ProductNotFound
+			}
+			return err
+		}
+
+		axes, err := r.loadMatrixAxes(tx, matrix.Axes)
+		if err != nil {
+			return err
+		}
+
+		template := matrix.SKUTemplate
+		if template == "" {
+			template = defaultSKUTemplate(axes)
+		}
+		price := product.Price
+		if matrix.Price != nil {
+			if matrix.Price.Currency != price.Currency {
+				return pkg.CurrencyMismatch.Withf("Variant price must be in %s, the currency of its product",
+					price.Currency)
+			}
+			price = *matrix.Price
+		}
+
+		var existing []entity.Variant
+		if err := tx.Preload("Attributes").Where("product_id = ?", product.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		existingKeys := make(map[string]bool, len(existing))
+		for _, v := range existing {
+			existingKeys[attributeSetKey(v.Attributes)] = true
+		}
+
+		var toCreate []entity.Variant
+		skus := make(map[string]bool)
+		for _, combo := range cartesian(axes) {
+			sku, err := renderSKU(template, &product, axes, combo)
+			if err != nil {
+				return err
+			}
+			variant := entity.Variant{
+				ProductID:        product.ID,
+				SKU:              sku,
+				Price:            price,
+				Stock:            matrix.Stock,
+				ReorderThreshold: matrix.ReorderThreshold,
+				Attributes:       combo,
+			}
+			if existingKeys[attributeSetKey(combo)] {
+				result.Skipped = append(result.Skipped, *toVariantResponse(&variant))
+				continue
+			}
+			if skus[sku] {
+				return pkg.SKUTemplateNotUnique
+			}
+			skus[sku] = true
+			toCreate = append(toCreate, variant)
+		}
+
+		if !matrix.DryRun && len(toCreate) > 0 {
+			if err := tx.Omit("Attributes.*").CreateInBatches(&toCreate, 100).Error; err != nil {
+				return err
+			}
+			for i := range toCreate {
+				if err := setDefaultStock(tx, toCreate[i].ID, matrix.Stock, "Initial stock", actor); err != nil {
+					return err
+				}
+			}
+		}
+		for i := range toCreate {
+			result.Variants = append(result.Variants, *toVariantResponse(&toCreate[i]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+	return result, nil
+}
+
+// loadMatrixAxes resolves each requested axis to its attribute and values,
+// making sure every value belongs to the attribute it is listed under.
+func (r *VariantRepository) loadMatrixAxes(tx *gorm.DB, reqAxes []request.VariantAxisRequest) ([]matrixAxis, error) {
+	axes := make([]matrixAxis, 0, len(reqAxes))
+	seen := make(map[uint]bool, len(reqAxes))
+	total := 1
+	for _, a := range reqAxes {
+		if seen[a.AttributeID] {
+			return nil, pkg.VariantAttributeConflict
+		}
+		seen[a.AttributeID] = true
+
+		var attribute entity.Attribute
+		if err := tx.First(&attribute, "id = ?", a.AttributeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, pkg.AttributeNotFound
+			}
+			return nil, err
+		}
+
+		ids := uniqueIDs(a.AttributeValueIDs)
+		var values []entity.AttributeValue
+		if err := tx.Where("id IN ? AND attribute_id = ?", ids, attribute.ID).
+			Order("id").
+			Find(&values).Error; err != nil {
+			return nil, err
+		}
+		if len(values) != len(ids) {
+			return nil, pkg.AttributeValueNotFound
+		}
+		for i := range values {
+			values[i].Attribute = attribute
+		}
+
+		total *= len(values)
+		if total > maxMatrixVariants {
+			return nil, pkg.TooManyVariants
+		}
+		axes = append(axes, matrixAxis{attribute: attribute, values: values})
+	}
+	return axes, nil
+}
+
+// cartesian returns every combination that picks one value from each axis,
+// in axis order.
+func cartesian(axes []matrixAxis) [][]entity.AttributeValue {
+	combos := [][]entity.AttributeValue{{}}
+	for _, axis := range axes {
+		next := make([][]entity.AttributeValue, 0, len(combos)*len(axis.values))
+		for _, combo := range combos {
+			for _, v := range axis.values {
+				c := make([]entity.AttributeValue, len(combo), len(combo)+1)
+				copy(c, combo)
+				next = append(next, append(c, v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+func defaultSKUTemplate(axes []matrixAxis) string {
+	var b strings.Builder
+	b.WriteString("{product}")
+	for _, axis := range axes {
+		b.WriteString("-{" + strings.ToLower(axis.attribute.Name) + "}")
+	}
+	return b.String()
+}
+
+// renderSKU fills a template such as "{product}-{color}-{size}". {product} is
+// the product name, {product_id} its ID, and any other placeholder is matched
+// case-insensitively against the attribute names of the axes.
+func renderSKU(template string, product *entity.Product, axes []matrixAxis, combo []entity.AttributeValue) (
+	string, error) {
+	var renderErr error
+	sku := skuPlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+		key := strings.ToLower(strings.TrimSpace(m[1 : len(m)-1]))
+		var source string
+		switch key {
+		case "product":
+			source = product.Name
+		case "product_id":
+			return pkg.UintToString(product.ID)
+		default:
+			i := slices.IndexFunc(axes, func(axis matrixAxis) bool {
+				return strings.ToLower(axis.attribute.Name) == key
+			})
+			if i < 0 {
+				renderErr = pkg.InvalidSKUTemplate
+				return m
+			}
+			source = combo[i].Value
+		}
+
+		token := skuToken(source)
+		if token == "" && renderErr == nil {
+			renderErr = pkg.EmptySKUToken.Withf("%q has no letters or digits to put in {%s}", source, key)
+		}
+		return token
+	})
+	if renderErr != nil {
+		return "", renderErr
+	}
+	return sku, nil
+}
+
+// skuToken transliterates s the way slugs are, so "Crème" becomes "CREME"
+// rather than a fragment of its ASCII letters. It is empty when s has no
+// Latin letters or digits.
+func skuToken(s string) string {
+	return strings.ToUpper(pkg.Slugify(s))
+}
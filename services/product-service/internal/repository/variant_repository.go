@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VariantRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantRepository(db *gorm.DB) *VariantRepository {
+	return &VariantRepository{db: db}
+}
+
+func (r *VariantRepository) checkIfProductExists(tx *gorm.DB, productID string) bool {
+	var product entity.Product
+	err := tx.Select("id").First(&product, "id = ?", productID).Error
+	return err == nil
+}
+
+// checkPriceCurrency makes sure a variant price is in the currency of its
+// product.
+func checkPriceCurrency(tx *gorm.DB, productID string, price pkg.Money) error {
+	var product entity.Product
+	if err := tx.Select("id, price_currency").First(&product, "id = ?", productID).Error; err != nil {
+		return err
+	}
+	if price.Currency != product.Price.Currency {
+		return pkg.CurrencyMismatch.Withf("Variant price must be in %s, the currency of its product",
+			product.Price.Currency)
+	}
+	return nil
+}
+
+func (r *VariantRepository) GetVariantsByProductID(productID string, q *pkg.ListQuery) (
+	[]response.VariantResponse, int64, error) {
+	if !r.checkIfProductExists(r.db, productID) {
+		return nil, 0, pkg.ProductNotFound
+	}
+
+	query := q.Filter(r.db.Model(&entity.Variant{}).Where("product_id = ?", productID))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var variants []entity.Variant
+	if err := q.Page(query.Preload("Attributes.Attribute")).Find(&variants).Error; err != nil {
+		return nil, 0, err
+	}
+	return toVariantResponses(variants), total, nil
+}
+
+func (r *VariantRepository) GetVariantByID(productID, variantID string) (*response.VariantResponse, error) {
+	variant, err := r.findVariant(r.db, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	return toVariantResponse(variant), nil
+}
+
+// GetVariantStock breaks the stock of a variant down by warehouse.
+func (r *VariantRepository) GetVariantStock(productID, variantID string) ([]response.WarehouseStockResponse, error) {
+	variant, err := r.findVariant(r.db, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := []response.WarehouseStockResponse{}
+	if err := stockLevels(r.db).
+		Where("s.variant_id = ?", variant.ID).
+		Order("w.priority, w.id").
+		Scan(&stock).Error; err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+func (r *VariantRepository) AddVariant(productID string, variant *request.VariantRequest, actor string) (
+	*response.VariantResponse, error) {
+	var created entity.Variant
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if !r.checkIfProductExists(tx, productID) {
+			return pkg.ProductNotFound
+		}
+		if err := checkPriceCurrency(tx, productID, variant.Price); err != nil {
+			return err
+		}
+
+		values, err := r.loadAttributeValues(tx, variant.AttributeValueIDs)
+		if err != nil {
+			return err
+		}
+		if err := r.checkDuplicateAttributes(tx, productID, 0, values); err != nil {
+			return err
+		}
+
+		created = entity.Variant{
+			ProductID:        pkg.StringToUint(productID),
+			SKU:              variant.SKU,
+			Price:            variant.Price,
+			Stock:            variant.Stock,
+			ReorderThreshold: variant.ReorderThreshold,
+			Attributes:       values,
+		}
+		if err := tx.Omit("Attributes.*").Create(&created).Error; err != nil {
+			return err
+		}
+		return setDefaultStock(tx, created.ID, variant.Stock, "Initial stock", actor)
+	})
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+	return r.GetVariantByID(productID, pkg.UintToString(created.ID))
+}
+
+func (r *VariantRepository) UpdateVariant(productID, variantID string, variant *request.VariantPatchRequest, actor string) (
+	*response.VariantResponse, error) {
+	updates := map[string]interface{}{}
+	if variant.SKU != nil {
+		updates["sku"] = *variant.SKU
+	}
+	if variant.Price != nil {
+		updates["price_amount"] = variant.Price.Amount
+		updates["price_currency"] = variant.Price.Currency
+	}
+	if variant.ReorderThreshold.Set {
+		updates["reorder_threshold"] = variant.ReorderThreshold.Value
+	}
+	if len(updates) == 0 && variant.Stock == nil && variant.AttributeValueIDs == nil {
+		return nil, pkg.NoFieldsToUpdate
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := r.findVariant(tx, productID, variantID)
+		if err != nil {
+			return err
+		}
+		if variant.Price != nil {
+			if err := checkPriceCurrency(tx, productID, *variant.Price); err != nil {
+				return err
+			}
+		}
+
+		if variant.AttributeValueIDs != nil {
+			values, err := r.loadAttributeValues(tx, *variant.AttributeValueIDs)
+			if err != nil {
+				return err
+			}
+			if err := r.checkDuplicateAttributes(tx, productID, existing.ID, values); err != nil {
+				return err
+			}
+			if err := tx.Model(existing).Omit("Attributes.*").Association("Attributes").Replace(values); err != nil {
+				return err
+			}
+		}
+
+		if variant.Stock != nil {
+			if err := setDefaultStock(tx, existing.ID, *variant.Stock, "Stock updated", actor); err != nil {
+				return err
+			}
+		}
+
+		if len(updates) > 0 {
+			return tx.Model(&entity.Variant{}).Where("id = ?", existing.ID).Updates(updates).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+	return r.GetVariantByID(productID, variantID)
+}
+
+// DeleteVariant soft-deletes a variant. It refuses while the variant holds
+// active reservations.
+func (r *VariantRepository) DeleteVariant(productID, variantID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		variant, err := r.findVariant(tx, productID, variantID)
+		if err != nil {
+			return err
+		}
+		// Reserving locks the same stock rows, so no reservation can be taken
+		// between this check and the delete.
+		if err := lockStockRows(tx, []uint{variant.ID}); err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&entity.StockReservation{}).
+			Where("variant_id = ? AND status = ?", variant.ID, entity.ReservationStatusActive).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return pkg.VariantHasReservations
+		}
+		return tx.Delete(&entity.Variant{}, "id = ?", variant.ID).Error
+	})
+}
+
+// RestoreVariant undeletes a variant as long as its SKU and attribute values
+// are not used by a live sibling in the meantime.
+func (r *VariantRepository) RestoreVariant(productID, variantID string) (*response.VariantResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		variant, err := r.findDeletedVariant(tx, productID, variantID)
+		if err != nil || !variant.DeletedAt.Valid {
+			return err
+		}
+		if err := r.checkDuplicateAttributes(tx, productID, variant.ID, variant.Attributes); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&entity.Variant{}).
+			Where("id = ?", variant.ID).
+			Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, mapVariantError(err)
+	}
+	return r.GetVariantByID(productID, variantID)
+}
+
+// PurgeVariant permanently removes a soft-deleted variant and its attribute
+// links.
+func (r *VariantRepository) PurgeVariant(productID, variantID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		variant, err := r.findDeletedVariant(tx, productID, variantID)
+		if err != nil {
+			return err
+		}
+		if !variant.DeletedAt.Valid {
+			return pkg.RecordNotDeleted
+		}
+		return purgeVariant(tx, variant)
+	})
+}
+
+// findDeletedVariant loads a variant of a live product whether or not the
+// variant itself is soft-deleted.
+func (r *VariantRepository) findDeletedVariant(tx *gorm.DB, productID, variantID string) (*entity.Variant, error) {
+	if !r.checkIfProductExists(tx, productID) {
+		return nil, pkg.ProductNotFound
+	}
+
+	var variant entity.Variant
+	if err := tx.Unscoped().Preload("Attributes").
+		First(&variant, "id = ? AND product_id = ?", variantID, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.VariantNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// purgeVariant removes a variant with its attribute links, warehouse stock,
+// movements, finished reservations, stock subscriptions and notifications. It
+// refuses while the variant still holds active reservations.
+func purgeVariant(tx *gorm.DB, variant *entity.Variant) error {
+	var active int64
+	if err := tx.Model(&entity.StockReservation{}).
+		Where("variant_id = ? AND status = ?", variant.ID, entity.ReservationStatusActive).
+		Count(&active).Error; err != nil {
+		return err
+	}
+	if active > 0 {
+		return pkg.VariantHasReservations
+	}
+
+	if err := tx.Model(variant).Association("Attributes").Clear(); err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&entity.StockReservation{}, &entity.WarehouseStock{},
+		&entity.StockMovement{}, &entity.StockSubscription{}, &entity.StockNotification{}} {
+		if err := tx.Unscoped().Where("variant_id = ?", variant.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Delete(&entity.Variant{}, variant.ID).Error
+}
+
+func (r *VariantRepository) findVariant(tx *gorm.DB, productID, variantID string) (*entity.Variant, error) {
+	if !r.checkIfProductExists(tx, productID) {
+		return nil, pkg.ProductNotFound
+	}
+
+	var variant entity.Variant
+	if err := tx.Preload("Attributes.Attribute").
+		First(&variant, "id = ? AND product_id = ?", variantID, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.VariantNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// loadAttributeValues fetches the requested attribute values, failing if any
+// of them does not exist or if two of them belong to the same attribute.
+func (r *VariantRepository) loadAttributeValues(tx *gorm.DB, ids []uint) ([]entity.AttributeValue, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return []entity.AttributeValue{}, nil
+	}
+
+	var values []entity.AttributeValue
+	if err := tx.Where("id IN ?", ids).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	if len(values) != len(ids) {
+		return nil, pkg.AttributeValueNotFound
+	}
+
+	seen := make(map[uint]bool, len(values))
+	for _, v := range values {
+		if seen[v.AttributeID] {
+			return nil, pkg.VariantAttributeConflict
+		}
+		seen[v.AttributeID] = true
+	}
+	return values, nil
+}
+
+// checkDuplicateAttributes rejects an attribute-value set that is already used
+// by another variant of the same product. excludeID skips the variant being
+// updated. The product row is locked first, so concurrent writers to the same
+// product check and write their variants one at a time.
+func (r *VariantRepository) checkDuplicateAttributes(tx *gorm.DB, productID string, excludeID uint,
+	values []entity.AttributeValue) error {
+	if err := lockProduct(tx, productID); err != nil {
+		return err
+	}
+
+	var siblings []entity.Variant
+	if err := tx.Preload("Attributes").
+		Where("product_id = ? AND id <> ?", productID, excludeID).
+		Find(&siblings).Error; err != nil {
+		return err
+	}
+
+	key := attributeSetKey(values)
+	for _, s := range siblings {
+		if attributeSetKey(s.Attributes) == key {
+			return pkg.DuplicateVariantAttributes
+		}
+	}
+	return nil
+}
+
+// lockProduct locks the product row for the rest of the transaction.
+func lockProduct(tx *gorm.DB, productID string) error {
+	var product entity.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&product, "id = ?", productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return pkg.ProductNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func attributeSetKey(values []entity.AttributeValue) string {
+	ids := make([]uint, 0, len(values))
+	for _, v := range values {
+		ids = append(ids, v.ID)
+	}
+	slices.Sort(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, pkg.UintToString(id))
+	}
+	return strings.Join(parts, ",")
+}
+
+func uniqueIDs(ids []uint) []uint {
+	res := slices.Clone(ids)
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+func mapVariantError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return pkg.DuplicateEntry.Withf("Variant SKU already exists")
+		case "23514":
+			return pkg.StockBelowReserved
+		}
+	}
+	return err
+}
+
+func toVariantResponse(v *entity.Variant) *response.VariantResponse {
+	attrs := make([]response.VariantAttributeResponse, 0, len(v.Attributes))
+	for _, a := range v.Attributes {
+		attrs = append(attrs, response.VariantAttributeResponse{
+			AttributeID:      a.AttributeID,
+			AttributeName:    a.Attribute.Name,
+			AttributeValueID: a.ID,
+			Value:            a.Value,
+		})
+	}
+	return &response.VariantResponse{
+		ID:               v.ID,
+		ProductID:        v.ProductID,
+		SKU:              v.SKU,
+		Price:            v.Price,
+		Stock:            v.Stock,
+		Reserved:         v.Reserved,
+		Available:        v.Stock - v.Reserved,
+		ReorderThreshold: v.ReorderThreshold,
+		LowStock:         v.ReorderThreshold != nil && v.Stock-v.Reserved < *v.ReorderThreshold,
+		Attributes:       attrs,
+		DeletedAt:        deletedAt(v.DeletedAt),
+	}
+}
+
+func toVariantResponses(variants []entity.Variant) []response.VariantResponse {
+	res := make([]response.VariantResponse, 0, len(variants))
+	for i := range variants {
+		res = append(res, *toVariantResponse(&variants[i]))
+	}
+	return res
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WarehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{db: db}
+}
+
+func (r *WarehouseRepository) GetAllWarehouses(q *pkg.ListQuery) ([]response.WarehouseResponse, int64, error) {
+	query := q.Filter(r.db.Model(&entity.Warehouse{}))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var warehouses []entity.Warehouse
+	if err := q.Page(query).Find(&warehouses).Error; err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]response.WarehouseResponse, 0, len(warehouses))
+	for i := range warehouses {
+		res = append(res, *toWarehouseResponse(&warehouses[i]))
+	}
+	return res, total, nil
+}
+
+func (r *WarehouseRepository) GetWarehouseByID(id string) (*response.WarehouseResponse, error) {
+	warehouse, err := findWarehouse(r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return toWarehouseResponse(warehouse), nil
+}
+
+// AddWarehouse creates a warehouse. Creating it as the default takes the
+// default flag away from the previous one.
+func (r *WarehouseRepository) AddWarehouse(warehouse *request.WarehouseRequest) (*response.WarehouseResponse, error) {
+	created := entity.Warehouse{
+		Code:      warehouse.Code,
+		Name:      warehouse.Name,
+		Priority:  warehouse.Priority,
+		IsDefault: warehouse.IsDefault,
+		Latitude:  warehouse.Latitude,
+		Longitude: warehouse.Longitude,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if created.IsDefault {
+			if err := clearDefaultWarehouse(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&created).Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Warehouse code already exists")
+		}
+		return nil, err
+	}
+	return toWarehouseResponse(&created), nil
+}
+
+func (r *WarehouseRepository) UpdateWarehouse(id string, warehouse *request.WarehousePatchRequest) (
+	*response.WarehouseResponse, error) {
+	updates := map[string]interface{}{}
+	if warehouse.Code != nil {
+		updates["code"] = *warehouse.Code
+	}
+	if warehouse.Name != nil {
+		updates["name"] = *warehouse.Name
+	}
+	if warehouse.Priority != nil {
+		updates["priority"] = *warehouse.Priority
+	}
+	if warehouse.IsDefault != nil {
+		updates["is_default"] = *warehouse.IsDefault
+	}
+	if warehouse.Latitude.Set {
+		updates["latitude"] = warehouse.Latitude.Value
+	}
+	if warehouse.Longitude.Set {
+		updates["longitude"] = warehouse.Longitude.Value
+	}
+	if len(updates) == 0 {
+		return nil, pkg.NoFieldsToUpdate
+	}
+
+	var updated entity.Warehouse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findWarehouse(tx, id)
+		if err != nil {
+			return err
+		}
+		if warehouse.IsDefault != nil && *warehouse.IsDefault != existing.IsDefault {
+			if existing.IsDefault {
+				return pkg.DefaultWarehouseRequired
+			}
+			if err := clearDefaultWarehouse(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&updated).Clauses(clause.Returning{}).
+			Where("id = ?", existing.ID).
+			Updates(updates).Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, pkg.DuplicateEntry.Withf("Warehouse code already exists")
+		}
+		return nil, err
+	}
+	return toWarehouseResponse(&updated), nil
+}
+
+// DeleteWarehouse soft-deletes a warehouse once it holds no stock. The
+// default warehouse cannot be deleted. Stock is only written to warehouses
+// locked through lockWarehouse, so none can arrive between the check and the
+// delete.
+func (r *WarehouseRepository) DeleteWarehouse(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		warehouse, err := lockWarehouse(tx, id)
+		if err != nil {
+			return err
+		}
+		if warehouse.IsDefault {
+			return pkg.DefaultWarehouseRequired
+		}
+
+		var stocked int64
+		if err := tx.Model(&entity.WarehouseStock{}).
+			Where("warehouse_id = ? AND (quantity > 0 OR reserved > 0)", warehouse.ID).
+			Count(&stocked).Error; err != nil {
+			return err
+		}
+		if stocked > 0 {
+			return pkg.WarehouseNotEmpty
+		}
+
+		if err := tx.Where("warehouse_id = ?", warehouse.ID).Delete(&entity.WarehouseStock{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(warehouse).Error
+	})
+}
+
+// GetWarehouseStock lists the stock of live variants held in a warehouse.
+func (r *WarehouseRepository) GetWarehouseStock(id string, q *pkg.ListQuery) (
+	[]response.WarehouseStockResponse, int64, error) {
+	warehouse, err := findWarehouse(r.db, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := q.Filter(stockLevels(r.db).Where("s.warehouse_id = ?", warehouse.ID))
+
+	var total int64
+	if err := q.Count(query, &total); err != nil {
+		return nil, 0, err
+	}
+
+	var stock []response.WarehouseStockResponse
+	if err := q.Page(query).Scan(&stock).Error; err != nil {
+		return nil, 0, err
+	}
+	return stock, total, nil
+}
+
+// SetWarehouseStock sets the quantity of a variant held in a warehouse, as
+// after a stock count. It cannot go below what is reserved there.
+func (r *WarehouseRepository) SetWarehouseStock(id, variantID string, stock *request.WarehouseStockRequest,
+	actor string) (*response.WarehouseStockResponse, error) {
+	var res response.WarehouseStockResponse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		warehouse, err := lockWarehouse(tx, id)
+		if err != nil {
+			return err
+		}
+		var variant entity.Variant
+		if err := tx.Select("id").First(&variant, "id = ?", variantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.VariantNotFound
+			}
+			return err
+		}
+
+		if err := setStockQuantity(tx, variant.ID, warehouse.ID, stock.Quantity, "Stock count", actor); err != nil {
+			return err
+		}
+		if err := syncVariantStock(tx, variant.ID); err != nil {
+			return err
+		}
+		return stockLevels(tx).
+			Where("s.warehouse_id = ? AND s.variant_id = ?", warehouse.ID, variant.ID).
+			Scan(&res).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// TransferStock moves available units of a variant from one warehouse to
+// another, recorded as a pair of transfer movements sharing a reference.
+// Reserved units stay where they are.
+func (r *WarehouseRepository) TransferStock(transfer *request.StockTransferRequest, actor string) (
+	[]response.StockMovementResponse, error) {
+	if transfer.FromWarehouseID == transfer.ToWarehouseID {
+		return nil, pkg.InvalidTransfer
+	}
+	reference := transfer.Reference
+	if reference == "" {
+		reference = "transfer:" + uuid.NewString()
+	}
+
+	var movements []entity.StockMovement
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Warehouses are locked in ID order as well.
+		for _, id := range slices.Sorted(slices.Values([]uint{transfer.FromWarehouseID, transfer.ToWarehouseID})) {
+			if _, err := lockWarehouse(tx, pkg.UintToString(id)); err != nil {
+				if errors.Is(err, pkg.WarehouseNotFound) {
+					return pkg.WarehouseNotFound.Withf("Warehouse %d not found", id)
+				}
+				return err
+			}
+		}
+		var variant entity.Variant
+		if err := tx.Select("id").First(&variant, transfer.VariantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkg.VariantNotFound
+			}
+			return err
+		}
+
+		// Both rows are locked in ID order so opposite transfers of the same
+		// variant cannot deadlock.
+		var locked []entity.WarehouseStock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("variant_id = ? AND warehouse_id IN ?", variant.ID,
+				[]uint{transfer.FromWarehouseID, transfer.ToWarehouseID}).
+			Order("id").
+			Find(&locked).Error; err != nil {
+			return err
+		}
+		available := 0
+		for _, s := range locked {
+			if s.WarehouseID == transfer.FromWarehouseID {
+				available = s.Quantity - s.Reserved
+			}
+		}
+		if available < transfer.Quantity {
+			return pkg.InsufficientStock.Withf("Warehouse %d does not have %d units of variant %d available",
+				transfer.FromWarehouseID, transfer.Quantity, variant.ID)
+		}
+
+		movements = []entity.StockMovement{
+			{WarehouseID: transfer.FromWarehouseID, Delta: -transfer.Quantity},
+			{WarehouseID: transfer.ToWarehouseID, Delta: transfer.Quantity},
+		}
+		for i := range movements {
+			movements[i].VariantID = variant.ID
+			movements[i].Type = entity.StockMovementTransfer
+			movements[i].Reason = transfer.Reason
+			movements[i].Actor = actor
+			movements[i].Reference = reference
+			if err := moveStock(tx, &movements[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toStockMovementResponses(movements), nil
+}
+
+func findWarehouse(tx *gorm.DB, id string) (*entity.Warehouse, error) {
+	var warehouse entity.Warehouse
+	if err := tx.First(&warehouse, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.WarehouseNotFound
+		}
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// lockWarehouse is findWarehouse taking a row lock, which every path writing
+// stock into a warehouse holds so that DeleteWarehouse cannot run meanwhile.
+func lockWarehouse(tx *gorm.DB, id string) (*entity.Warehouse, error) {
+	return findWarehouse(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func clearDefaultWarehouse(tx *gorm.DB) error {
+	return tx.Model(&entity.Warehouse{}).Where("is_default").Update("is_default", false).Error
+}
+
+// stockLevels selects warehouse stock rows of live variants in live
+// warehouses, shaped as WarehouseStockResponse.
+func stockLevels(db *gorm.DB) *gorm.DB {
+	return db.Table("warehouse_stocks s").
+		Select("s.warehouse_id, w.code AS warehouse_code, s.variant_id, v.sku, s.quantity, s.reserved, " +
+			"s.quantity - s.reserved AS available, s.updated_at").
+		Joins("JOIN warehouses w ON w.id = s.warehouse_id AND w.deleted_at IS NULL").
+		Joins("JOIN variants v ON v.id = s.variant_id AND v.deleted_at IS NULL")
+}
+
+func toWarehouseResponse(w *entity.Warehouse) *response.WarehouseResponse {
+	return &response.WarehouseResponse{
+		ID:        w.ID,
+		Code:      w.Code,
+		Name:      w.Name,
+		Priority:  w.Priority,
+		IsDefault: w.IsDefault,
+		Latitude:  w.Latitude,
+		Longitude: w.Longitude,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
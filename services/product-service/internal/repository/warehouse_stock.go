@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/request"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Per-warehouse rows in warehouse_stocks hold the current stock and are
+// only ever changed through moveStock, which records each change in the
+// stock_movements ledger. Variant.Stock and Variant.Reserved are totals over
+// the rows, recomputed by syncVariantStock in the same transaction as every
+// change so listing and filtering variants by stock stays a single-table
+// query.
+
+// defaultWarehouseID returns the warehouse that receives stock set through
+// the variant API.
+func defaultWarehouseID(tx *gorm.DB) (uint, error) {
+	var warehouse entity.Warehouse
+	if err := tx.Select("id").Where("is_default").First(&warehouse).Error; err != nil {
+		return 0, err
+	}
+	return warehouse.ID, nil
+}
+
+// moveStock applies a ledger movement to the stock row of its variant and
+// warehouse, creating the row if needed, and records the movement. It fails
+// with StockBelowReserved if the row would end up with less on hand than
+// reserved, or with a negative quantity.
+func moveStock(tx *gorm.DB, movement *entity.StockMovement) error {
+	var current entity.WarehouseStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id = ? AND warehouse_id = ?", movement.VariantID, movement.WarehouseID).
+		Limit(1).
+		Find(&current).Error; err != nil {
+		return err
+	}
+	if current.ID == 0 {
+		if movement.Delta < 0 || movement.ReservedDelta < 0 {
+			return pkg.StockBelowReserved
+		}
+		// The row starts empty and takes the movement like any other row. An
+		// empty row inserted concurrently is just as good, so a conflict is
+		// not an error.
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.WarehouseStock{
+			VariantID:   movement.VariantID,
+			WarehouseID: movement.WarehouseID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	// The check constraint is evaluated on the updated row, so it only fails
+	// when the result really is below what is reserved.
+	err := tx.Model(&entity.WarehouseStock{}).
+		Where("variant_id = ? AND warehouse_id = ?", movement.VariantID, movement.WarehouseID).
+		Updates(map[string]interface{}{
+			"quantity": gorm.Expr("quantity + ?", movement.Delta),
+			"reserved": gorm.Expr("reserved + ?", movement.ReservedDelta),
+		}).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+		return pkg.StockBelowReserved
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Create(movement).Error
+}
+
+// lockStockRows locks every warehouse stock row of the given variants in ID
+// order. Paths changing rows of several warehouses or variants take their
+// locks through it, or in the same ID order, so they cannot deadlock on each
+// other.
+func lockStockRows(tx *gorm.DB, variantIDs []uint) error {
+	var ids []uint
+	return tx.Model(&entity.WarehouseStock{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id IN ?", variantIDs).
+		Order("id").
+		Pluck("id", &ids).Error
+}
+
+// setStockQuantity sets the quantity of a variant held in a warehouse,
+// recording the difference as an adjustment.
+func setStockQuantity(tx *gorm.DB, variantID, warehouseID uint, quantity int, reason, actor string) error {
+	var current entity.WarehouseStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("variant_id = ? AND warehouse_id = ?", variantID, warehouseID).
+		Limit(1).
+		Find(&current).Error; err != nil {
+		return err
+	}
+	if quantity == current.Quantity {
+		return nil
+	}
+	return moveStock(tx, &entity.StockMovement{
+		VariantID:   variantID,
+		WarehouseID: warehouseID,
+		Type:        entity.StockMovementAdjustment,
+		Delta:       quantity - current.Quantity,
+		Reason:      reason,
+		Actor:       actor,
+	})
+}
+
+// setDefaultStock sets the quantity of a variant held in the default
+// warehouse, which is what stock means when written through the variant API.
+func setDefaultStock(tx *gorm.DB, variantID uint, quantity int, reason, actor string) error {
+	warehouseID, err := resolveWarehouseID(tx, nil)
+	if err != nil {
+		return err
+	}
+	if err := setStockQuantity(tx, variantID, warehouseID, quantity, reason, actor); err != nil {
+		return err
+	}
+	return syncVariantStock(tx, variantID)
+}
+
+// resolveWarehouseID checks that the given live warehouse exists, or returns
+// the default warehouse when id is nil. Either one is locked like
+// lockWarehouse does, since the caller is about to write stock into it.
+func resolveWarehouseID(tx *gorm.DB, id *uint) (uint, error) {
+	if id != nil {
+		warehouse, err := lockWarehouse(tx, pkg.UintToString(*id))
+		if err != nil {
+			return 0, err
+		}
+		return warehouse.ID, nil
+	}
+	warehouseID, err := defaultWarehouseID(tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, pkg.DefaultWarehouseRequired.Withf("No default warehouse is configured")
+	}
+	return warehouseID, err
+}
+
+// syncVariantStock recomputes Variant.Stock and Variant.Reserved from the
+// warehouse rows of the given variants, soft-deleted ones included, and
+// queues the stock notifications the change calls for. Variants without any
+// warehouse row end up with zero.
+func syncVariantStock(tx *gorm.DB, variantIDs ...uint) error {
+	if len(variantIDs) == 0 {
+		return nil
+	}
+
+	// The old totals are read under the same row locks the update takes, so
+	// concurrent changes see each other's results in turn.
+	var before []stockTransition
+	if err := tx.Unscoped().Model(&entity.Variant{}).
+		Select("id, stock AS old_stock, reserved AS old_reserved").
+		Where("id IN ?", variantIDs).
+		Order("id").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scan(&before).Error; err != nil {
+		return err
+	}
+
+	var after []stockTransition
+	if err := tx.Raw(`
+		UPDATE variants v
+		SET stock = coalesce(s.quantity, 0), reserved = coalesce(s.reserved, 0), updated_at = now()
+		FROM variants x
+		LEFT JOIN (
+			SELECT variant_id, sum(quantity) AS quantity, sum(reserved) AS reserved
+			FROM warehouse_stocks
+			WHERE variant_id IN ?
+			GROUP BY variant_id
+		) s ON s.variant_id = x.id
+		WHERE v.id = x.id AND x.id IN ?
+		RETURNING v.id, v.sku, v.stock, v.reserved, v.reorder_threshold, v.deleted_at`,
+		variantIDs, variantIDs).Scan(&after).Error; err != nil {
+		return err
+	}
+
+	old := make(map[uint]stockTransition, len(before))
+	for _, t := range before {
+		old[t.ID] = t
+	}
+	for i := range after {
+		after[i].OldStock = old[after[i].ID].OldStock
+		after[i].OldReserved = old[after[i].ID].OldReserved
+	}
+	return queueStockNotifications(tx, after)
+}
+
+type stockAllocation struct {
+	WarehouseID uint
+	Quantity    int
+}
+
+type allocatableStock struct {
+	WarehouseID uint
+	Available   int
+	Priority    int
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// allocateStock picks the warehouses quantity units of a variant are taken
+// from, locking their stock rows. Warehouses are drained in the order of the
+// allocation rule; it fails with InsufficientStock if all of them together
+// cannot cover the quantity.
+func allocateStock(tx *gorm.DB, variantID uint, quantity int, rule string, lat, lng *float64) (
+	[]stockAllocation, error) {
+	var variant entity.Variant
+	if err := tx.Select("id").First(&variant, variantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkg.VariantNotFound.Withf("Variant %d not found", variantID)
+		}
+		return nil, err
+	}
+
+	var stocks []allocatableStock
+	if err := tx.Table("warehouse_stocks s").
+		Select("s.warehouse_id, s.quantity - s.reserved AS available, w.priority, w.latitude, w.longitude").
+		Joins("JOIN warehouses w ON w.id = s.warehouse_id AND w.deleted_at IS NULL").
+		Where("s.variant_id = ? AND s.quantity > s.reserved", variantID).
+		Order("s.id").
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "s"}}).
+		Scan(&stocks).Error; err != nil {
+		return nil, err
+	}
+	// Rows are locked in ID order, like lockStockRows does, and only then
+	// put in allocation order.
+	allocations, remaining := planAllocation(stocks, quantity, rule, lat, lng)
+	if remaining > 0 {
+		return nil, pkg.InsufficientStock.Withf("Not enough stock available for variant %d", variantID)
+	}
+	return allocations, nil
+}
+
+// planAllocation sorts stocks into the order of the allocation rule and
+// drains them until quantity is covered. Warehouses are ranked by priority,
+// then ID; the nearest rule ranks them by distance from lat, lng first. It
+// returns the allocations and the quantity left uncovered.
+func planAllocation(stocks []allocatableStock, quantity int, rule string, lat, lng *float64) (
+	[]stockAllocation, int) {
+	slices.SortFunc(stocks, func(a, b allocatableStock) int {
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), cmp.Compare(a.WarehouseID, b.WarehouseID))
+	})
+	if rule == request.AllocationNearest && lat != nil && lng != nil {
+		// Stable, so warehouses at the same distance or without a location
+		// keep priority order.
+		slices.SortStableFunc(stocks, func(a, b allocatableStock) int {
+			return cmp.Compare(distanceKm(a.Latitude, a.Longitude, *lat, *lng),
+				distanceKm(b.Latitude, b.Longitude, *lat, *lng))
+		})
+	}
+
+	var allocations []stockAllocation
+	remaining := quantity
+	for _, s := range stocks {
+		if remaining == 0 {
+			break
+		}
+		take := min(s.Available, remaining)
+		allocations = append(allocations, stockAllocation{WarehouseID: s.WarehouseID, Quantity: take})
+		remaining -= take
+	}
+	return allocations, remaining
+}
+
+// distanceKm is the great-circle distance between a warehouse and a point;
+// warehouses without a location are infinitely far away.
+func distanceKm(lat1, lng1 *float64, lat2, lng2 float64) float64 {
+	if lat1 == nil || lng1 == nil {
+		return math.Inf(1)
+	}
+	const earthRadiusKm = 6371
+	rad := math.Pi / 180
+	dLat := (lat2 - *lat1) * rad
+	dLng := (lng2 - *lng1) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(*lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// BackfillWarehouseStock makes sure a default warehouse exists and moves the
+// stock of variants that predate warehouses into it, along with their active
+// reservations.
+func BackfillWarehouseStock(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		warehouseID, err := defaultWarehouseID(tx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			main := entity.Warehouse{Code: "MAIN", Name: "Main warehouse", IsDefault: true}
+			if err := tx.Create(&main).Error; err != nil {
+				return err
+			}
+			warehouseID = main.ID
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO warehouse_stocks (variant_id, warehouse_id, quantity, reserved, created_at, updated_at)
+			SELECT v.id, ?, greatest(v.stock, v.reserved, 0), v.reserved, now(), now()
+			FROM variants v
+			WHERE NOT EXISTS (SELECT 1 FROM warehouse_stocks s WHERE s.variant_id = v.id)`,
+			warehouseID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.StockReservation{}).
+			Where("warehouse_id IS NULL").
+			Update("warehouse_id", warehouseID).Error
+	})
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"product-service/internal/request"
+)
+
+func ptr(f float64) *float64 { return &f }
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng *float64
+		toLat    float64
+		toLng    float64
+		want     float64
+	}{
+		{"same point", ptr(52.52), ptr(13.405), 52.52, 13.405, 0},
+		{"Berlin to Paris", ptr(52.52), ptr(13.405), 48.8566, 2.3522, 878},
+		{"across the antimeridian", ptr(0), ptr(179.5), 0, -179.5, 111},
+		{"pole to pole", ptr(90), ptr(0), -90, 0, 20015},
+		{"no location", nil, nil, 48.8566, 2.3522, math.Inf(1)},
+		{"no longitude", ptr(52.52), nil, 48.8566, 2.3522, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distanceKm(tt.lat, tt.lng, tt.toLat, tt.toLng)
+			if math.IsInf(tt.want, 1) {
+				if !math.IsInf(got, 1) {
+					t.Errorf("distanceKm = %v, want +Inf", got)
+				}
+				return
+			}
+			if math.Abs(got-tt.want) > 1 {
+				t.Errorf("distanceKm = %.1f, want %.0f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanAllocation(t *testing.T) {
+	// Paris, Berlin, Madrid and one warehouse without a location, listed in
+	// stock row order.
+	stocks := func() []allocatableStock {
+		return []allocatableStock{
+			{WarehouseID: 1, Available: 5, Priority: 2, Latitude: ptr(48.8566), Longitude: ptr(2.3522)},
+			{WarehouseID: 2, Available: 5, Priority: 1, Latitude: ptr(52.52), Longitude: ptr(13.405)},
+			{WarehouseID: 3, Available: 5, Priority: 1, Latitude: ptr(40.4168), Longitude: ptr(-3.7038)},
+			{WarehouseID: 4, Available: 5, Priority: 0},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		quantity      int
+		rule          string
+		lat, lng      *float64
+		want          []stockAllocation
+		wantRemaining int
+	}{
+		{
+			name:     "priority then ID",
+			quantity: 12,
+			rule:     request.AllocationPriority,
+			want:     []stockAllocation{{4, 5}, {2, 5}, {3, 2}},
+		},
+		{
+			name:     "single warehouse covers it",
+			quantity: 3,
+			rule:     request.AllocationPriority,
+			want:     []stockAllocation{{4, 3}},
+		},
+		{
+			name:     "nearest to Lyon",
+			quantity: 12,
+			rule:     request.AllocationNearest,
+			lat:      ptr(45.764),
+			lng:      ptr(4.8357),
+			want:     []stockAllocation{{1, 5}, {3, 5}, {2, 2}},
+		},
+		{
+			name:     "warehouses without a location come last",
+			quantity: 17,
+			rule:     request.AllocationNearest,
+			lat:      ptr(52.52),
+			lng:      ptr(13.405),
+			want:     []stockAllocation{{2, 5}, {1, 5}, {3, 5}, {4, 2}},
+		},
+		{
+			name:     "nearest without a location falls back to priority",
+			quantity: 7,
+			rule:     request.AllocationNearest,
+			want:     []stockAllocation{{4, 5}, {2, 2}},
+		},
+		{
+			name:          "not enough stock",
+			quantity:      25,
+			rule:          request.AllocationPriority,
+			want:          []stockAllocation{{4, 5}, {2, 5}, {3, 5}, {1, 5}},
+			wantRemaining: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, remaining := planAllocation(stocks(), tt.quantity, tt.rule, tt.lat, tt.lng)
+			if !reflect.DeepEqual(got, tt.want) || remaining != tt.wantRemaining {
+				t.Errorf("planAllocation = %v, %d; want %v, %d", got, remaining, tt.want, tt.wantRemaining)
+			}
+		})
+	}
+}
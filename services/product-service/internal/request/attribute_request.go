@@ -0,0 +1,23 @@
+package request
+
+type AttributeRequest struct {
+	Name string `json:"name" validate:"required"`
+	Type string `json:"type,omitempty" validate:"omitempty,oneof=text color number"`
+	Unit string `json:"unit,omitempty"`
+}
+
+type AttributePatchRequest struct {
+	Name *string `json:"name,omitempty"`
+	Type *string `json:"type,omitempty" validate:"omitempty,oneof=text color number"`
+	Unit *string `json:"unit,omitempty"`
+}
+
+type AttributeValueRequest struct {
+	Value  string `json:"value" validate:"required"`
+	Swatch string `json:"swatch,omitempty" validate:"omitempty,hexcolor"`
+}
+
+type AttributeValuePatchRequest struct {
+	Value  *string `json:"value,omitempty"`
+	Swatch *string `json:"swatch,omitempty" validate:"omitempty,hexcolor"`
+}
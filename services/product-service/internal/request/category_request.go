@@ -1,13 +1,33 @@
 package request
 
+import "product-service/internal/pkg"
+
 type CategoryRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description,omitempty"`
 	ParentID    *uint  `json:"parent_id,omitempty"`
 }
 
+// CategoryPatchRequest updates only the fields present in the body. An
+// explicit "parent_id": null moves the category to the top level.
 type CategoryPatchRequest struct {
-	Name        *string `json:"name,omitempty"`
-	Description *string `json:"description,omitempty"`
-	ParentID    *uint   `json:"parent_id,omitempty"`
+	Name        *string            `json:"name,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	ParentID    pkg.Nullable[uint] `json:"parent_id"`
+}
+
+// CategoryMoveRequest moves a category and its subtree. A null or missing
+// parent_id moves it to the top level; without a position it is appended
+// after its new siblings.
+type CategoryMoveRequest struct {
+	ParentID *uint `json:"parent_id"`
+	Position *int  `json:"position,omitempty" validate:"omitempty,gte=0"`
+}
+
+type CategoryOrderRequest struct {
+	CategoryIDs []uint `json:"category_ids" validate:"required,min=1,dive,required"`
+}
+
+type CategoryMergeRequest struct {
+	TargetID uint `json:"target_id" validate:"required"`
 }
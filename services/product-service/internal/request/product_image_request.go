@@ -0,0 +1,10 @@
+package request
+
+type ProductImagePatchRequest struct {
+	IsPrimary *bool `json:"is_primary,omitempty"`
+	Position  *int  `json:"position,omitempty" validate:"omitempty,gte=0"`
+}
+
+type ProductImageOrderRequest struct {
+	ImageIDs []uint `json:"image_ids" validate:"required,min=1,dive,required"`
+}
@@ -0,0 +1,18 @@
+package request
+
+import "product-service/internal/pkg"
+
+type ProductRequest struct {
+	Name        string    `json:"name" validate:"required"`
+	Description string    `json:"description,omitempty"`
+	CategoryID  uint      `json:"category_id" validate:"required"`
+	Price       pkg.Money `json:"price" validate:"money"`
+	CreatedBy   uint      `json:"created_by" validate:"required"`
+}
+
+type ProductPatchRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	Price       *pkg.Money `json:"price,omitempty" validate:"omitempty,money"`
+}
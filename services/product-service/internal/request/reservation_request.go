@@ -0,0 +1,26 @@
+package request
+
+// Allocation rules choose the warehouses a reservation draws stock from.
+const (
+	AllocationPriority = "priority"
+	AllocationNearest  = "nearest"
+)
+
+type ReservationItemRequest struct {
+	VariantID uint `json:"variant_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+}
+
+// ReservationRequest holds stock for every item of an order. Items naming
+// the same variant are added together.
+//
+// Stock is taken from warehouses in allocation order, moving on to the next
+// one when a warehouse runs short: "priority" (the default) follows warehouse
+// priority, "nearest" the distance from latitude/longitude.
+type ReservationRequest struct {
+	OrderRef   string                   `json:"order_ref" validate:"required,max=100"`
+	Items      []ReservationItemRequest `json:"items" validate:"required,min=1,dive"`
+	Allocation string                   `json:"allocation,omitempty" validate:"omitempty,oneof=priority nearest"`
+	Latitude   *float64                 `json:"latitude,omitempty" validate:"required_if=Allocation nearest,omitempty,gte=-90,lte=90"`
+	Longitude  *float64                 `json:"longitude,omitempty" validate:"required_if=Allocation nearest,omitempty,gte=-180,lte=180"`
+}
@@ -0,0 +1,7 @@
+package request
+
+// StockSubscriptionRequest registers interest in an out-of-stock variant.
+type StockSubscriptionRequest struct {
+	SKU   string `json:"sku" validate:"required"`
+	Email string `json:"email" validate:"required,email,max=254"`
+}
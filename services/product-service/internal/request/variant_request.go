@@ -0,0 +1,40 @@
+package request
+
+import "product-service/internal/pkg"
+
+// VariantRequest creates a variant whose stock is held in the default
+// warehouse; use the warehouse stock endpoints to stock other warehouses.
+type VariantRequest struct {
+	SKU               string    `json:"sku" validate:"required"`
+	Price             pkg.Money `json:"price" validate:"money"`
+	Stock             int       `json:"stock" validate:"gte=0"`
+	ReorderThreshold  *int      `json:"reorder_threshold,omitempty" validate:"omitempty,gte=0"`
+	AttributeValueIDs []uint    `json:"attribute_value_ids" validate:"dive,required"`
+}
+
+// VariantPatchRequest updates only the fields present in the body. Stock
+// sets the quantity held in the default warehouse, so the stock reported for
+// the variant also includes what other warehouses hold. A null
+// reorder_threshold turns the low-stock alert off.
+type VariantPatchRequest struct {
+	SKU               *string           `json:"sku,omitempty"`
+	Price             *pkg.Money        `json:"price,omitempty" validate:"omitempty,money"`
+	Stock             *int              `json:"stock,omitempty" validate:"omitempty,gte=0"`
+	ReorderThreshold  pkg.Nullable[int] `json:"reorder_threshold" validate:"omitempty,gte=0"`
+	AttributeValueIDs *[]uint           `json:"attribute_value_ids,omitempty" validate:"omitempty,dive,required"`
+}
+
+type VariantAxisRequest struct {
+	AttributeID       uint   `json:"attribute_id" validate:"required"`
+	AttributeValueIDs []uint `json:"attribute_value_ids" validate:"required,min=1,dive,required"`
+}
+
+type VariantMatrixRequest struct {
+	Axes        []VariantAxisRequest `json:"axes" validate:"required,min=1,dive"`
+	SKUTemplate string               `json:"sku_template,omitempty"`
+	Price       *pkg.Money           `json:"price,omitempty" validate:"omitempty,money"`
+	Stock       int                  `json:"stock" validate:"gte=0"`
+	// ReorderThreshold applies to every generated variant.
+	ReorderThreshold *int `json:"reorder_threshold,omitempty" validate:"omitempty,gte=0"`
+	DryRun           bool `json:"dry_run"`
+}
@@ -0,0 +1,50 @@
+package request
+
+import "product-service/internal/pkg"
+
+type WarehouseRequest struct {
+	Code      string   `json:"code" validate:"required,max=32"`
+	Name      string   `json:"name" validate:"required"`
+	Priority  int      `json:"priority"`
+	IsDefault bool     `json:"is_default"`
+	Latitude  *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+}
+
+// WarehousePatchRequest updates only the fields present in the body; null
+// coordinates clear the warehouse location. A default warehouse stops being
+// the default only by making another one the default.
+type WarehousePatchRequest struct {
+	Code      *string               `json:"code,omitempty" validate:"omitempty,max=32"`
+	Name      *string               `json:"name,omitempty"`
+	Priority  *int                  `json:"priority,omitempty"`
+	IsDefault *bool                 `json:"is_default,omitempty"`
+	Latitude  pkg.Nullable[float64] `json:"latitude"`
+	Longitude pkg.Nullable[float64] `json:"longitude"`
+}
+
+type WarehouseStockRequest struct {
+	Quantity int `json:"quantity" validate:"gte=0"`
+}
+
+// StockTransferRequest moves available units between warehouses. Reference
+// defaults to a generated one shared by both movements of the transfer.
+type StockTransferRequest struct {
+	VariantID       uint   `json:"variant_id" validate:"required"`
+	FromWarehouseID uint   `json:"from_warehouse_id" validate:"required"`
+	ToWarehouseID   uint   `json:"to_warehouse_id" validate:"required"`
+	Quantity        int    `json:"quantity" validate:"required,gt=0"`
+	Reason          string `json:"reason,omitempty" validate:"max=255"`
+	Reference       string `json:"reference,omitempty" validate:"max=100"`
+}
+
+// StockMovementRequest records stock received, returned or adjusted after a
+// count in a warehouse, the default one if omitted. Receipts and returns add
+// stock; adjustments may go either way.
+type StockMovementRequest struct {
+	WarehouseID *uint  `json:"warehouse_id,omitempty"`
+	Type        string `json:"type" validate:"required,oneof=receipt return adjustment"`
+	Delta       int    `json:"delta" validate:"required"`
+	Reason      string `json:"reason,omitempty" validate:"max=255"`
+	Reference   string `json:"reference,omitempty" validate:"max=100"`
+}
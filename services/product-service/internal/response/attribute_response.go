@@ -0,0 +1,16 @@
+package response
+
+type AttributeValueResponse struct {
+	ID          uint   `json:"id"`
+	AttributeID uint   `json:"attribute_id"`
+	Value       string `json:"value"`
+	Swatch      string `json:"swatch,omitempty"`
+}
+
+type AttributeResponse struct {
+	ID     uint                     `json:"id"`
+	Name   string                   `json:"name"`
+	Type   string                   `json:"type"`
+	Unit   string                   `json:"unit,omitempty"`
+	Values []AttributeValueResponse `json:"values,omitempty"`
+}
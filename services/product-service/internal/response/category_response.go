@@ -1,13 +1,35 @@
 package response
 
+import "time"
+
 type CategoryResponse struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	ParentID    *uint  `json:"parent_id,omitempty"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	ParentID    *uint     `json:"parent_id,omitempty"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// DeletedAt is only set on soft-deleted categories, which are listed
+	// with ?include_deleted=true.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CategoryTreeResponse struct {
 	CategoryResponse
 	Children []*CategoryTreeResponse `json:"children,omitempty"`
 }
+
+type CategoryBreadcrumbResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type CategoryDetailResponse struct {
+	CategoryResponse
+	// Breadcrumbs runs from the top-level category down to this one and is
+	// only set when requested.
+	Breadcrumbs []CategoryBreadcrumbResponse `json:"breadcrumbs,omitempty"`
+}
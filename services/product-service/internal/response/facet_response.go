@@ -0,0 +1,37 @@
+package response
+
+type ProductFacets struct {
+	Categories []CategoryFacet  `json:"categories"`
+	Attributes []AttributeFacet `json:"attributes"`
+	Prices     []PriceFacet     `json:"prices"`
+}
+
+type CategoryFacet struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type AttributeFacet struct {
+	ID     uint                  `json:"id"`
+	Name   string                `json:"name"`
+	Type   string                `json:"type"`
+	Values []AttributeValueFacet `json:"values"`
+}
+
+type AttributeValueFacet struct {
+	ID     uint   `json:"id"`
+	Value  string `json:"value"`
+	Swatch string `json:"swatch,omitempty"`
+	Count  int64  `json:"count"`
+}
+
+// PriceFacet is a half-open price range [Min, Max) in one currency. Every
+// currency of the matching products gets its own set of ranges; the first
+// bucket has no Min and the last has no Max.
+type PriceFacet struct {
+	Currency string   `json:"currency"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Count    int64    `json:"count"`
+}
@@ -0,0 +1,13 @@
+package response
+
+type ProductImageResponse struct {
+	ID              uint              `json:"id"`
+	ProductID       uint              `json:"product_id"`
+	URL             string            `json:"url"`
+	ContentType     string            `json:"content_type,omitempty"`
+	Size            int64             `json:"size,omitempty"`
+	IsPrimary       bool              `json:"is_primary"`
+	Position        int               `json:"position"`
+	RenditionStatus string            `json:"rendition_status,omitempty"`
+	Renditions      map[string]string `json:"renditions,omitempty"` // rendition name, e.g. "thumbnail", to URL
+}
@@ -0,0 +1,31 @@
+package response
+
+import (
+	"product-service/internal/pkg"
+	"time"
+)
+
+type ProductResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	Description string     `json:"description,omitempty"`
+	CategoryID  uint       `json:"category_id"`
+	Price       pkg.Money  `json:"price"`
+	CreatedBy   uint       `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// Images holds every image on detail responses and only the primary
+	// image on list responses.
+	Images []ProductImageResponse `json:"images,omitempty"`
+}
+
+type ProductSearchResponse struct {
+	ProductResponse
+	CategoryName       string   `json:"category_name"`
+	Rank               float64  `json:"rank"`
+	NameHighlight      string   `json:"name_highlight"`
+	DescriptionSnippet string   `json:"description_snippet,omitempty"`
+	MatchedSKUs        []string `json:"matched_skus,omitempty"`
+}
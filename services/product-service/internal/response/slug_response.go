@@ -0,0 +1,9 @@
+package response
+
+// SlugRedirectResponse tells clients that requested an outdated slug where
+// the resource lives now.
+type SlugRedirectResponse struct {
+	ID       uint   `json:"id"`
+	Slug     string `json:"slug"`
+	Location string `json:"location"`
+}
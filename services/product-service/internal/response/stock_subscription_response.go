@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+// StockSubscriptionResponse is a back-in-stock subscription; NotifiedAt is
+// set once the notification has been queued.
+type StockSubscriptionResponse struct {
+	ID         uint       `json:"id"`
+	VariantID  uint       `json:"variant_id"`
+	SKU        string     `json:"sku"`
+	Email      string     `json:"email"`
+	NotifiedAt *time.Time `json:"notified_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
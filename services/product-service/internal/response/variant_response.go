@@ -0,0 +1,46 @@
+package response
+
+import (
+	"product-service/internal/pkg"
+	"time"
+)
+
+type VariantAttributeResponse struct {
+	AttributeID      uint   `json:"attribute_id"`
+	AttributeName    string `json:"attribute_name"`
+	AttributeValueID uint   `json:"attribute_value_id"`
+	Value            string `json:"value"`
+}
+
+type VariantResponse struct {
+	ID        uint      `json:"id"`
+	ProductID uint      `json:"product_id"`
+	SKU       string    `json:"sku"`
+	Price     pkg.Money `json:"price"`
+	Stock     int       `json:"stock"`
+	Reserved  int       `json:"reserved"`
+	Available int       `json:"available"`
+	// LowStock reports Available below ReorderThreshold.
+	ReorderThreshold *int                       `json:"reorder_threshold"`
+	LowStock         bool                       `json:"low_stock"`
+	Attributes       []VariantAttributeResponse `json:"attributes"`
+	DeletedAt        *time.Time                 `json:"deleted_at,omitempty"`
+}
+
+type VariantMatrixResponse struct {
+	DryRun   bool              `json:"dry_run"`
+	Variants []VariantResponse `json:"variants"`
+	Skipped  []VariantResponse `json:"skipped"`
+}
+
+type ReservationResponse struct {
+	ID          uint      `json:"id"`
+	OrderRef    string    `json:"order_ref"`
+	VariantID   uint      `json:"variant_id"`
+	WarehouseID *uint     `json:"warehouse_id,omitempty"`
+	Quantity    int       `json:"quantity"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,54 @@
+package response
+
+import "time"
+
+type WarehouseResponse struct {
+	ID        uint      `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Priority  int       `json:"priority"`
+	IsDefault bool      `json:"is_default"`
+	Latitude  *float64  `json:"latitude,omitempty"`
+	Longitude *float64  `json:"longitude,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WarehouseStockResponse struct {
+	WarehouseID   uint      `json:"warehouse_id"`
+	WarehouseCode string    `json:"warehouse_code"`
+	VariantID     uint      `json:"variant_id"`
+	SKU           string    `json:"sku"`
+	Quantity      int       `json:"quantity"`
+	Reserved      int       `json:"reserved"`
+	Available     int       `json:"available"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// StockMovementResponse is an entry of the stock ledger. Balance is the
+// quantity of the variant on hand across all warehouses after the movement;
+// it is only filled in by the stock history.
+type StockMovementResponse struct {
+	ID            uint      `json:"id"`
+	VariantID     uint      `json:"variant_id"`
+	WarehouseID   uint      `json:"warehouse_id"`
+	Type          string    `json:"type"`
+	Delta         int       `json:"delta"`
+	ReservedDelta int       `json:"reserved_delta"`
+	Balance       *int      `json:"balance,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	Actor         string    `json:"actor,omitempty"`
+	Reference     string    `json:"reference,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockDriftResponse reports a variant whose stored totals disagree with its
+// stock ledger.
+type StockDriftResponse struct {
+	VariantID      uint   `json:"variant_id"`
+	SKU            string `json:"sku"`
+	Stock          int    `json:"stock"`
+	LedgerStock    int    `json:"ledger_stock"`
+	Reserved       int    `json:"reserved"`
+	LedgerReserved int    `json:"ledger_reserved"`
+}
@@ -3,8 +3,11 @@ package internal
 import (
 	"product-service/internal/handler"
 	"product-service/internal/pkg"
+	"product-service/internal/rendition"
 	"product-service/internal/repository"
+	"product-service/internal/storage"
 	"product-service/internal/usecase"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -22,8 +25,129 @@ func RegisterCategoryRoutes(e *echo.Echo, db *gorm.DB) {
 	categoryGroup.POST("", pkg.BindAndValidate(categoryHandler.AddCategory))
 	categoryGroup.PATCH("/:id", pkg.BindAndValidate(categoryHandler.PatchCategory))
 	categoryGroup.DELETE("/:id", categoryHandler.DeleteCategory)
+	categoryGroup.POST("/:id/restore", categoryHandler.RestoreCategory)
+	categoryGroup.DELETE("/:id/purge", categoryHandler.PurgeCategory)
+	categoryGroup.POST("/:id/move", pkg.BindAndValidate(categoryHandler.MoveCategory))
+	categoryGroup.POST("/:id/merge", pkg.BindAndValidate(categoryHandler.MergeCategory))
+	categoryGroup.PUT("/order", pkg.BindAndValidate(categoryHandler.ReorderCategories))
+	categoryGroup.PUT("/:id/children/order", pkg.BindAndValidate(categoryHandler.ReorderCategories))
 	categoryGroup.GET("/tree", categoryHandler.GetCategoryTree)
 	categoryGroup.GET("/:id/children", categoryHandler.GetChildCategoriesByID)
+	categoryGroup.GET("/:id/path", categoryHandler.GetCategoryPath)
 
 	categoryGroup.GET("/:id/products", categoryHandler.GetProductsByCategoryID)
 }
+
+func RegisterProductRoutes(e *echo.Echo, db *gorm.DB, store storage.Storage) {
+	productGroup := e.Group("/products")
+
+	productRepo := repository.NewProductRepository(db)
+	productUsecase := usecase.NewProductUsecase(productRepo, store)
+	productHandler := handler.NewProductHandler(productUsecase)
+
+	productGroup.GET("", productHandler.GetAllProducts)
+	productGroup.GET("/search", productHandler.SearchProducts)
+	productGroup.GET("/:id", productHandler.GetProductByID)
+	productGroup.POST("", pkg.BindAndValidate(productHandler.AddProduct))
+	productGroup.PATCH("/:id", pkg.BindAndValidate(productHandler.PatchProduct))
+	productGroup.DELETE("/:id", productHandler.DeleteProduct)
+	productGroup.POST("/:id/restore", productHandler.RestoreProduct)
+	productGroup.DELETE("/:id/purge", productHandler.PurgeProduct)
+
+	variantRepo := repository.NewVariantRepository(db)
+	variantUsecase := usecase.NewVariantUsecase(variantRepo)
+	variantHandler := handler.NewVariantHandler(variantUsecase)
+
+	productGroup.GET("/:id/variants", variantHandler.GetVariantsByProductID)
+	productGroup.GET("/:id/variants/:variantId", variantHandler.GetVariantByID)
+	productGroup.GET("/:id/variants/:variantId/stock", variantHandler.GetVariantStock)
+	productGroup.POST("/:id/variants", pkg.BindAndValidate(variantHandler.AddVariant))
+	productGroup.POST("/:id/variants/generate", pkg.BindAndValidate(variantHandler.GenerateVariants))
+	productGroup.PATCH("/:id/variants/:variantId", pkg.BindAndValidate(variantHandler.PatchVariant))
+	productGroup.DELETE("/:id/variants/:variantId", variantHandler.DeleteVariant)
+	productGroup.POST("/:id/variants/:variantId/restore", variantHandler.RestoreVariant)
+	productGroup.DELETE("/:id/variants/:variantId/purge", variantHandler.PurgeVariant)
+
+	// Stock is tracked per variant regardless of product, so its ledger is
+	// addressed by variant ID alone.
+	variantGroup := e.Group("/variants")
+	variantGroup.GET("/:id/stock-history", variantHandler.GetStockHistory)
+	variantGroup.POST("/:id/stock-movements", pkg.BindAndValidate(variantHandler.AddStockMovement))
+}
+
+func RegisterAttributeRoutes(e *echo.Echo, db *gorm.DB) {
+	attributeGroup := e.Group("/attributes")
+
+	attributeRepo := repository.NewAttributeRepository(db)
+	attributeUsecase := usecase.NewAttributeUsecase(attributeRepo)
+	attributeHandler := handler.NewAttributeHandler(attributeUsecase)
+
+	attributeGroup.GET("", attributeHandler.GetAllAttributes)
+	attributeGroup.GET("/:id", attributeHandler.GetAttributeByID)
+	attributeGroup.POST("", pkg.BindAndValidate(attributeHandler.AddAttribute))
+	attributeGroup.PATCH("/:id", pkg.BindAndValidate(attributeHandler.PatchAttribute))
+	attributeGroup.DELETE("/:id", attributeHandler.DeleteAttribute)
+
+	attributeGroup.GET("/:id/values", attributeHandler.GetValuesByAttributeID)
+	attributeGroup.POST("/:id/values", pkg.BindAndValidate(attributeHandler.AddAttributeValue))
+	attributeGroup.PATCH("/:id/values/:valueId", pkg.BindAndValidate(attributeHandler.PatchAttributeValue))
+	attributeGroup.DELETE("/:id/values/:valueId", attributeHandler.DeleteAttributeValue)
+}
+
+func RegisterProductImageRoutes(e *echo.Echo, db *gorm.DB, store storage.Storage, renditions *rendition.Pool) {
+	imageGroup := e.Group("/products/:id/images")
+
+	imageRepo := repository.NewProductImageRepository(db)
+	imageUsecase := usecase.NewProductImageUsecase(imageRepo, store, renditions)
+	imageHandler := handler.NewProductImageHandler(imageUsecase)
+
+	imageGroup.GET("", imageHandler.GetImagesByProductID)
+	imageGroup.POST("", imageHandler.UploadImage)
+	imageGroup.PUT("/order", pkg.BindAndValidate(imageHandler.ReorderImages))
+	imageGroup.PATCH("/:imageId", pkg.BindAndValidate(imageHandler.PatchImage))
+	imageGroup.DELETE("/:imageId", imageHandler.DeleteImage)
+	imageGroup.POST("/:imageId/renditions", imageHandler.RegenerateRenditions)
+}
+
+func RegisterReservationRoutes(e *echo.Echo, db *gorm.DB, ttl time.Duration) {
+	reservationGroup := e.Group("/reservations")
+
+	reservationRepo := repository.NewReservationRepository(db)
+	reservationUsecase := usecase.NewReservationUsecase(reservationRepo, ttl)
+	reservationHandler := handler.NewReservationHandler(reservationUsecase)
+
+	reservationGroup.POST("", pkg.BindAndValidate(reservationHandler.Reserve))
+	reservationGroup.GET("/:orderRef", reservationHandler.GetReservations)
+	reservationGroup.POST("/:orderRef/commit", reservationHandler.Commit)
+	reservationGroup.POST("/:orderRef/release", reservationHandler.Release)
+}
+
+func RegisterWarehouseRoutes(e *echo.Echo, db *gorm.DB) {
+	warehouseGroup := e.Group("/warehouses")
+
+	warehouseRepo := repository.NewWarehouseRepository(db)
+	warehouseUsecase := usecase.NewWarehouseUsecase(warehouseRepo)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseUsecase)
+
+	warehouseGroup.GET("", warehouseHandler.GetAllWarehouses)
+	warehouseGroup.GET("/:id", warehouseHandler.GetWarehouseByID)
+	warehouseGroup.POST("", pkg.BindAndValidate(warehouseHandler.AddWarehouse))
+	warehouseGroup.PATCH("/:id", pkg.BindAndValidate(warehouseHandler.PatchWarehouse))
+	warehouseGroup.DELETE("/:id", warehouseHandler.DeleteWarehouse)
+
+	warehouseGroup.GET("/:id/stock", warehouseHandler.GetWarehouseStock)
+	warehouseGroup.PUT("/:id/stock/:variantId", pkg.BindAndValidate(warehouseHandler.SetWarehouseStock))
+	warehouseGroup.POST("/transfers", pkg.BindAndValidate(warehouseHandler.TransferStock))
+}
+
+func RegisterStockSubscriptionRoutes(e *echo.Echo, db *gorm.DB) {
+	subscriptionGroup := e.Group("/stock-subscriptions")
+
+	subscriptionRepo := repository.NewStockSubscriptionRepository(db)
+	subscriptionUsecase := usecase.NewStockSubscriptionUsecase(subscriptionRepo)
+	subscriptionHandler := handler.NewStockSubscriptionHandler(subscriptionUsecase)
+
+	subscriptionGroup.POST("", pkg.BindAndValidate(subscriptionHandler.Subscribe))
+	subscriptionGroup.GET("/:id", subscriptionHandler.GetSubscription)
+	subscriptionGroup.DELETE("/:id", subscriptionHandler.Unsubscribe)
+}
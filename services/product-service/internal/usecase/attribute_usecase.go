@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+)
+
+type AttributeUsecase struct {
+	attributeRepo *repository.AttributeRepository
+}
+
+func NewAttributeUsecase(attributeRepo *repository.AttributeRepository) *AttributeUsecase {
+	return &AttributeUsecase{attributeRepo: attributeRepo}
+}
+
+func (u *AttributeUsecase) GetAllAttributes(q *pkg.ListQuery) ([]response.AttributeResponse, int64, error) {
+	return u.attributeRepo.GetAllAttributes(q)
+}
+
+func (u *AttributeUsecase) GetAttributeByID(id string) (*response.AttributeResponse, error) {
+	return u.attributeRepo.GetAttributeByID(id)
+}
+
+func (u *AttributeUsecase) AddAttribute(attribute *request.AttributeRequest) (*response.AttributeResponse, error) {
+	return u.attributeRepo.AddAttribute(attribute)
+}
+
+func (u *AttributeUsecase) UpdateAttribute(id string, attribute *request.AttributePatchRequest) (
+	*response.AttributeResponse, error) {
+	return u.attributeRepo.UpdateAttribute(id, attribute)
+}
+
+func (u *AttributeUsecase) DeleteAttribute(id string) error {
+	return u.attributeRepo.DeleteAttribute(id)
+}
+
+func (u *AttributeUsecase) GetValuesByAttributeID(attributeID string, q *pkg.ListQuery) (
+	[]response.AttributeValueResponse, int64, error) {
+	return u.attributeRepo.GetValuesByAttributeID(attributeID, q)
+}
+
+func (u *AttributeUsecase) AddAttributeValue(attributeID string, value *request.AttributeValueRequest) (
+	*response.AttributeValueResponse, error) {
+	return u.attributeRepo.AddAttributeValue(attributeID, value)
+}
+
+func (u *AttributeUsecase) UpdateAttributeValue(attributeID, valueID string, value *request.AttributeValuePatchRequest) (
+	*response.AttributeValueResponse, error) {
+	return u.attributeRepo.UpdateAttributeValue(attributeID, valueID, value)
+}
+
+func (u *AttributeUsecase) DeleteAttributeValue(attributeID, valueID string) error {
+	return u.attributeRepo.DeleteAttributeValue(attributeID, valueID)
+}
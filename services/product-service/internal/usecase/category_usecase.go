@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"product-service/internal/pkg"
 	"product-service/internal/repository"
 	"product-service/internal/request"
 	"product-service/internal/response"
@@ -14,11 +15,11 @@ func NewCategoryUsecase(categoryRepo *repository.CategoryRepository) *CategoryUs
 	return &CategoryUsecase{categoryRepo: categoryRepo}
 }
 
-func (u *CategoryUsecase) GetAllCategories() ([]response.CategoryResponse, error) {
-	return u.categoryRepo.GetAllCategories()
+func (u *CategoryUsecase) GetAllCategories(q *pkg.ListQuery) ([]response.CategoryResponse, int64, error) {
+	return u.categoryRepo.GetAllCategories(q)
 }
 
-func (u *CategoryUsecase) AddCategory(category *request.CategoryRequest) error {
+func (u *CategoryUsecase) AddCategory(category *request.CategoryRequest) (*response.CategoryResponse, error) {
 	return u.categoryRepo.AddCategory(category)
 }
 
@@ -26,18 +27,67 @@ func (u *CategoryUsecase) UpdateCategory(id string, category *request.CategoryPa
 	return u.categoryRepo.UpdateCategory(id, category)
 }
 
-func (u *CategoryUsecase) GetCategoryByID(id string) (*response.CategoryResponse, error) {
-	return u.categoryRepo.GetCategoryByID(id)
+func (u *CategoryUsecase) GetCategoryByID(id string, withBreadcrumbs bool) (*response.CategoryDetailResponse, error) {
+	category, err := u.categoryRepo.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+	detail := &response.CategoryDetailResponse{CategoryResponse: *category}
+	if withBreadcrumbs {
+		if detail.Breadcrumbs, err = u.categoryRepo.GetCategoryPath(id); err != nil {
+			return nil, err
+		}
+	}
+	return detail, nil
 }
 
-func (u *CategoryUsecase) DeleteCategory(id string) error {
-	return u.categoryRepo.DeleteCategory(id)
+func (u *CategoryUsecase) ResolveCategorySlug(slug string) (uint, string, error) {
+	return u.categoryRepo.ResolveCategorySlug(slug)
 }
 
-func (u *CategoryUsecase) GetCategoryTree() ([]*response.CategoryTreeResponse, error) {
-	return u.categoryRepo.GetCategoryTree()
+func (u *CategoryUsecase) GetCategoryPath(id string) ([]response.CategoryBreadcrumbResponse, error) {
+	return u.categoryRepo.GetCategoryPath(id)
 }
 
-func (u *CategoryUsecase) GetChildCategoriesByID(id string) ([]response.CategoryResponse, error) {
-	return u.categoryRepo.GetChildCategoriesByID(id)
+func (u *CategoryUsecase) DeleteCategory(id string, cascade bool) error {
+	return u.categoryRepo.DeleteCategory(id, cascade)
+}
+
+func (u *CategoryUsecase) MoveCategory(id string, move *request.CategoryMoveRequest) (*response.CategoryResponse, error) {
+	return u.categoryRepo.MoveCategory(id, move)
+}
+
+func (u *CategoryUsecase) ReorderCategories(parentID *uint, order *request.CategoryOrderRequest) (
+	[]response.CategoryResponse, error) {
+	return u.categoryRepo.ReorderCategories(parentID, order)
+}
+
+func (u *CategoryUsecase) MergeCategory(id string, merge *request.CategoryMergeRequest) (*response.CategoryResponse, error) {
+	return u.categoryRepo.MergeCategory(id, merge.TargetID)
+}
+
+func (u *CategoryUsecase) GetCategoryTree(rootID *uint, maxDepth int) ([]*response.CategoryTreeResponse, error) {
+	return u.categoryRepo.GetCategoryTree(rootID, maxDepth)
+}
+
+func (u *CategoryUsecase) GetChildCategoriesByID(id string, q *pkg.ListQuery) ([]response.CategoryResponse, int64, error) {
+	return u.categoryRepo.GetChildCategoriesByID(id, q)
+}
+
+func (u *CategoryUsecase) GetProductsByCategoryID(id string, includeDescendants bool, q *pkg.ListQuery) (
+	[]response.ProductResponse, int64, error) {
+	return u.categoryRepo.GetProductsByCategoryID(id, includeDescendants, q)
+}
+
+func (u *CategoryUsecase) GetCategoryProductFacets(id string, includeDescendants bool, q *pkg.ListQuery,
+	buckets []float64) (*response.ProductFacets, error) {
+	return u.categoryRepo.GetCategoryProductFacets(id, includeDescendants, q, buckets)
+}
+
+func (u *CategoryUsecase) RestoreCategory(id string, cascade bool) (*response.CategoryResponse, error) {
+	return u.categoryRepo.RestoreCategory(id, cascade)
+}
+
+func (u *CategoryUsecase) PurgeCategory(id string) error {
+	return u.categoryRepo.PurgeCategory(id)
 }
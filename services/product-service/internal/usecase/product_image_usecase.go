@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"product-service/internal/entity"
+	"product-service/internal/pkg"
+	"product-service/internal/rendition"
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"product-service/internal/storage"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/labstack/gommon/log"
+)
+
+// MaxImageSize is the largest image upload accepted, in bytes.
+const MaxImageSize = 10 << 20
+
+var allowedImageTypes = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+
+type ProductImageUsecase struct {
+	imageRepo  *repository.ProductImageRepository
+	storage    storage.Storage
+	renditions *rendition.Pool
+}
+
+func NewProductImageUsecase(imageRepo *repository.ProductImageRepository, storage storage.Storage,
+	renditions *rendition.Pool) *ProductImageUsecase {
+	return &ProductImageUsecase{imageRepo: imageRepo, storage: storage, renditions: renditions}
+}
+
+func (u *ProductImageUsecase) GetImagesByProductID(productID string, q *pkg.ListQuery) (
+	[]response.ProductImageResponse, int64, error) {
+	return u.imageRepo.GetImagesByProductID(productID, q)
+}
+
+// UploadImage validates the file, stores the blob, records the image and
+// queues its renditions. The blob is removed again if the row cannot be
+// written.
+func (u *ProductImageUsecase) UploadImage(ctx context.Context, productID string, file io.Reader, isPrimary bool,
+	position *int) (*response.ProductImageResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(file, MaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageSize {
+		return nil, pkg.ImageTooLarge
+	}
+	mime := mimetype.Detect(data)
+	if !mimetype.EqualsAny(mime.String(), allowedImageTypes...) {
+		return nil, pkg.UnsupportedImageType
+	}
+	if !u.imageRepo.CheckIfProductExists(productID) {
+		return nil, pkg.ProductNotFound
+	}
+
+	key := fmt.Sprintf("products/%s/%s%s", productID, uuid.NewString(), mime.Extension())
+	if err := u.storage.Put(ctx, key, bytes.NewReader(data), mime.String()); err != nil {
+		return nil, err
+	}
+
+	image := &entity.ProductImage{
+		URL:         u.storage.URL(key),
+		StorageKey:  key,
+		ContentType: mime.String(),
+		Size:        int64(len(data)),
+		IsPrimary:   isPrimary,
+	}
+	created, err := u.imageRepo.AddImage(productID, image, position)
+	if err != nil {
+		if delErr := u.storage.Delete(ctx, key); delErr != nil {
+			log.Errorf("Failed to clean up blob %s: %v", key, delErr)
+		}
+		return nil, err
+	}
+	u.renditions.Enqueue(created.ID)
+	return created, nil
+}
+
+func (u *ProductImageUsecase) UpdateImage(productID, imageID string, patch *request.ProductImagePatchRequest) (
+	*response.ProductImageResponse, error) {
+	return u.imageRepo.UpdateImage(productID, imageID, patch)
+}
+
+func (u *ProductImageUsecase) ReorderImages(productID string, order *request.ProductImageOrderRequest) (
+	[]response.ProductImageResponse, error) {
+	return u.imageRepo.ReorderImages(productID, order)
+}
+
+// RegenerateRenditions discards the current status of an image and queues
+// it again, e.g. after the rendition configuration changed.
+func (u *ProductImageUsecase) RegenerateRenditions(productID, imageID string) error {
+	image, err := u.imageRepo.MarkRenditionsPending(productID, imageID)
+	if err != nil {
+		return err
+	}
+	u.renditions.Enqueue(image.ID)
+	return nil
+}
+
+// DeleteImage removes the rows first and then the blobs; a failed blob delete
+// is logged rather than reported since the image is already gone.
+func (u *ProductImageUsecase) DeleteImage(ctx context.Context, productID, imageID string) error {
+	image, err := u.imageRepo.DeleteImage(productID, imageID)
+	if err != nil {
+		return err
+	}
+	deleteImageBlobs(ctx, u.storage, image)
+	return nil
+}
+
+// deleteImageBlobs deletes the blobs of an image and its renditions whose
+// rows are already gone, logging the ones that fail.
+func deleteImageBlobs(ctx context.Context, store storage.Storage, image *entity.ProductImage) {
+	keys := []string{image.StorageKey}
+	for _, rd := range image.Renditions {
+		keys = append(keys, rd.StorageKey)
+	}
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Errorf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"product-service/internal/pkg"
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"product-service/internal/storage"
+)
+
+type ProductUsecase struct {
+	productRepo *repository.ProductRepository
+	storage     storage.Storage
+}
+
+func NewProductUsecase(productRepo *repository.ProductRepository, storage storage.Storage) *ProductUsecase {
+	return &ProductUsecase{productRepo: productRepo, storage: storage}
+}
+
+func (u *ProductUsecase) GetAllProducts(q *pkg.ListQuery) ([]response.ProductResponse, int64, error) {
+	return u.productRepo.GetAllProducts(q)
+}
+
+func (u *ProductUsecase) GetProductByID(id string) (*response.ProductResponse, error) {
+	return u.productRepo.GetProductByID(id)
+}
+
+func (u *ProductUsecase) ResolveProductSlug(slug string) (uint, string, error) {
+	return u.productRepo.ResolveProductSlug(slug)
+}
+
+func (u *ProductUsecase) AddProduct(product *request.ProductRequest) (*response.ProductResponse, error) {
+	return u.productRepo.AddProduct(product)
+}
+
+func (u *ProductUsecase) UpdateProduct(id string, product *request.ProductPatchRequest) (*response.ProductResponse, error) {
+	return u.productRepo.UpdateProduct(id, product)
+}
+
+func (u *ProductUsecase) DeleteProduct(id string) error {
+	return u.productRepo.DeleteProduct(id)
+}
+
+func (u *ProductUsecase) SearchProducts(term string, q *pkg.ListQuery) ([]response.ProductSearchResponse, int64, error) {
+	return u.productRepo.SearchProducts(term, q)
+}
+
+func (u *ProductUsecase) GetProductFacets(q *pkg.ListQuery, buckets []float64) (*response.ProductFacets, error) {
+	return u.productRepo.GetProductFacets(q, buckets)
+}
+
+func (u *ProductUsecase) GetSearchFacets(term string, q *pkg.ListQuery, buckets []float64) (*response.ProductFacets, error) {
+	return u.productRepo.GetSearchFacets(term, q, buckets)
+}
+
+func (u *ProductUsecase) RestoreProduct(id string) (*response.ProductResponse, error) {
+	return u.productRepo.RestoreProduct(id)
+}
+
+// PurgeProduct removes the product rows first and then the blobs of its
+// images, like DeleteImage does for a single image.
+func (u *ProductUsecase) PurgeProduct(ctx context.Context, id string) error {
+	images, err := u.productRepo.PurgeProduct(id)
+	if err != nil {
+		return err
+	}
+	for i := range images {
+		deleteImageBlobs(ctx, u.storage, &images[i])
+	}
+	return nil
+}
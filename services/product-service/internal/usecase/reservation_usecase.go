@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+	"time"
+)
+
+type ReservationUsecase struct {
+	reservationRepo *repository.ReservationRepository
+	ttl             time.Duration
+}
+
+// NewReservationUsecase creates a usecase whose reservations expire ttl
+// after they are made.
+func NewReservationUsecase(reservationRepo *repository.ReservationRepository, ttl time.Duration) *ReservationUsecase {
+	return &ReservationUsecase{reservationRepo: reservationRepo, ttl: ttl}
+}
+
+func (u *ReservationUsecase) Reserve(reservation *request.ReservationRequest, actor string) (
+	[]response.ReservationResponse, error) {
+	return u.reservationRepo.Reserve(reservation, time.Now().Add(u.ttl), actor)
+}
+
+func (u *ReservationUsecase) GetReservations(orderRef string) ([]response.ReservationResponse, error) {
+	return u.reservationRepo.GetReservations(orderRef)
+}
+
+func (u *ReservationUsecase) Commit(orderRef, actor string) ([]response.ReservationResponse, error) {
+	return u.reservationRepo.Commit(orderRef, actor)
+}
+
+func (u *ReservationUsecase) Release(orderRef, actor string) ([]response.ReservationResponse, error) {
+	return u.reservationRepo.Release(orderRef, actor)
+}
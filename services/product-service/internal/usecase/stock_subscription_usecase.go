@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+)
+
+type StockSubscriptionUsecase struct {
+	subscriptionRepo *repository.StockSubscriptionRepository
+}
+
+func NewStockSubscriptionUsecase(subscriptionRepo *repository.StockSubscriptionRepository) *StockSubscriptionUsecase {
+	return &StockSubscriptionUsecase{subscriptionRepo: subscriptionRepo}
+}
+
+func (u *StockSubscriptionUsecase) Subscribe(subscription *request.StockSubscriptionRequest) (
+	*response.StockSubscriptionResponse, error) {
+	return u.subscriptionRepo.Subscribe(subscription)
+}
+
+func (u *StockSubscriptionUsecase) GetSubscription(id string) (*response.StockSubscriptionResponse, error) {
+	return u.subscriptionRepo.GetSubscription(id)
+}
+
+func (u *StockSubscriptionUsecase) Unsubscribe(id string) error {
+	return u.subscriptionRepo.Unsubscribe(id)
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+)
+
+type VariantUsecase struct {
+	variantRepo *repository.VariantRepository
+}
+
+func NewVariantUsecase(variantRepo *repository.VariantRepository) *VariantUsecase {
+	return &VariantUsecase{variantRepo: variantRepo}
+}
+
+func (u *VariantUsecase) GetVariantsByProductID(productID string, q *pkg.ListQuery) (
+	[]response.VariantResponse, int64, error) {
+	return u.variantRepo.GetVariantsByProductID(productID, q)
+}
+
+func (u *VariantUsecase) GetVariantByID(productID, variantID string) (*response.VariantResponse, error) {
+	return u.variantRepo.GetVariantByID(productID, variantID)
+}
+
+func (u *VariantUsecase) AddVariant(productID string, variant *request.VariantRequest, actor string) (
+	*response.VariantResponse, error) {
+	return u.variantRepo.AddVariant(productID, variant, actor)
+}
+
+func (u *VariantUsecase) UpdateVariant(productID, variantID string, variant *request.VariantPatchRequest, actor string) (
+	*response.VariantResponse, error) {
+	return u.variantRepo.UpdateVariant(productID, variantID, variant, actor)
+}
+
+func (u *VariantUsecase) DeleteVariant(productID, variantID string) error {
+	return u.variantRepo.DeleteVariant(productID, variantID)
+}
+
+func (u *VariantUsecase) GenerateVariants(productID string, matrix *request.VariantMatrixRequest, actor string) (
+	*response.VariantMatrixResponse, error) {
+	return u.variantRepo.GenerateVariants(productID, matrix, actor)
+}
+
+func (u *VariantUsecase) GetVariantStock(productID, variantID string) ([]response.WarehouseStockResponse, error) {
+	return u.variantRepo.GetVariantStock(productID, variantID)
+}
+
+func (u *VariantUsecase) GetStockHistory(variantID string, q *pkg.ListQuery) (
+	[]response.StockMovementResponse, int64, error) {
+	return u.variantRepo.GetStockHistory(variantID, q)
+}
+
+func (u *VariantUsecase) AddStockMovement(variantID string, movement *request.StockMovementRequest, actor string) (
+	*response.StockMovementResponse, error) {
+	return u.variantRepo.AddStockMovement(variantID, movement, actor)
+}
+
+func (u *VariantUsecase) RestoreVariant(productID, variantID string) (*response.VariantResponse, error) {
+	return u.variantRepo.RestoreVariant(productID, variantID)
+}
+
+func (u *VariantUsecase) PurgeVariant(productID, variantID string) error {
+	return u.variantRepo.PurgeVariant(productID, variantID)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"product-service/internal/pkg"
+	"product-service/internal/repository"
+	"product-service/internal/request"
+	"product-service/internal/response"
+)
+
+type WarehouseUsecase struct {
+	warehouseRepo *repository.WarehouseRepository
+}
+
+func NewWarehouseUsecase(warehouseRepo *repository.WarehouseRepository) *WarehouseUsecase {
+	return &WarehouseUsecase{warehouseRepo: warehouseRepo}
+}
+
+func (u *WarehouseUsecase) GetAllWarehouses(q *pkg.ListQuery) ([]response.WarehouseResponse, int64, error) {
+	return u.warehouseRepo.GetAllWarehouses(q)
+}
+
+func (u *WarehouseUsecase) GetWarehouseByID(id string) (*response.WarehouseResponse, error) {
+	return u.warehouseRepo.GetWarehouseByID(id)
+}
+
+func (u *WarehouseUsecase) AddWarehouse(warehouse *request.WarehouseRequest) (*response.WarehouseResponse, error) {
+	return u.warehouseRepo.AddWarehouse(warehouse)
+}
+
+func (u *WarehouseUsecase) UpdateWarehouse(id string, warehouse *request.WarehousePatchRequest) (
+	*response.WarehouseResponse, error) {
+	return u.warehouseRepo.UpdateWarehouse(id, warehouse)
+}
+
+func (u *WarehouseUsecase) DeleteWarehouse(id string) error {
+	return u.warehouseRepo.DeleteWarehouse(id)
+}
+
+func (u *WarehouseUsecase) GetWarehouseStock(id string, q *pkg.ListQuery) (
+	[]response.WarehouseStockResponse, int64, error) {
+	return u.warehouseRepo.GetWarehouseStock(id, q)
+}
+
+func (u *WarehouseUsecase) SetWarehouseStock(id, variantID string, stock *request.WarehouseStockRequest,
+	actor string) (*response.WarehouseStockResponse, error) {
+	return u.warehouseRepo.SetWarehouseStock(id, variantID, stock, actor)
+}
+
+func (u *WarehouseUsecase) TransferStock(transfer *request.StockTransferRequest, actor string) (
+	[]response.StockMovementResponse, error) {
+	return u.warehouseRepo.TransferStock(transfer, actor)
+}